@@ -1,15 +1,21 @@
 package main
 
 import (
-	"ReNaming/internal/renamer"
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io/fs"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/leotang315/ReNaming/pkg/renamer"
 )
 
 func main() {
@@ -17,21 +23,84 @@ func main() {
 	// --- 1. Define command line parameters ---
 	path := flag.String("path", "", "Comma-separated list of files and/or directories to process.")
 	pattern := flag.String("pattern", "*", "Glob pattern to filter files in directories.")
+	includeFiles := flag.Bool("files", true, "Include regular files as rename targets.")
+	includeDirs := flag.Bool("dirs", false, "Include directories as rename targets.")
+	dirPattern := flag.String("dir-pattern", "*", "Glob pattern to filter directories when -dirs is set.")
 	recursive := flag.Bool("recursive", false, "Process subdirectories recursively.")
+	var include, exclude, includeRegexp, excludeRegexp listFlag
+	flag.Var(&include, "include", "Glob pattern a file must match, may be repeated. A file is selected if it matches any of them.")
+	flag.Var(&exclude, "exclude", "Glob pattern of files and directories to skip, may be repeated. Excluded directories are not descended into.")
+	flag.Var(&includeRegexp, "include-regex", "Regular expression a file must match, may be repeated.")
+	flag.Var(&excludeRegexp, "exclude-regex", "Regular expression of files and directories to skip, may be repeated.")
+	matchPath := flag.Bool("match-path", false, "Match patterns against the path relative to the walked directory instead of the name. ** matches any number of directories.")
+	maxDepth := flag.Int("max-depth", 0, "Maximum directory depth with -recursive, 0 means unlimited.")
+	skipHidden := flag.Bool("skip-hidden", false, "Skip files and directories whose name starts with a dot.")
+	minSize := flag.String("min-size", "", "Minimum file size in bytes, K, M or G suffixes allowed, e.g. 100K.")
+	maxSize := flag.String("max-size", "", "Maximum file size in bytes, K, M or G suffixes allowed, e.g. 2M.")
+	newer := flag.String("newer", "", "Only files modified after this time, as 2006-01-02 or RFC 3339.")
+	older := flag.String("older", "", "Only files modified before this time, as 2006-01-02 or RFC 3339.")
 	ruleFile := flag.String("ruleFile", "", "Path to the JSON config file containing renaming rules.")
 	ruleJSON := flag.String("rule", "", "Renaming rules as a JSON array string. For single rule, wrap it in square brackets.")
 	dryRun := flag.Bool("dry-run", false, "Preview changes without actually renaming files.")
 	mappingFile := flag.String("mapping", "", "Path to the JSON file containing renaming mappings.")
 	outputFile := flag.String("output", "", "Path to save the results as JSON file.")
+	root := flag.String("root", "", "Sandbox root directory. Mappings with a source or target outside it are rejected.")
+	allowMove := flag.Bool("allow-move", false, "Allow rules and mappings to move entries into another directory.")
+	allowExec := flag.Bool("allow-exec", false, "Allow exec rules to run external commands. Only enable it for rule files you trust.")
+	platform := flag.String("platform", "auto", "Target platform for filename validation: auto, windows, linux, darwin or portable.")
+	conflict := flag.String("conflict", "error", "How to handle colliding targets: error, skip or suffix.")
+	workers := flag.Int("workers", 0, "Number of goroutines generating mappings, 0 means one per CPU.")
+	jsonLines := flag.Bool("jsonl", false, "Stream results as JSON Lines, one result per line, with bounded memory. -mapping is then read as JSON Lines too.")
+	parallel := flag.Int("parallel", 1, "Number of renames executed at the same time, useful on high-latency network filesystems.")
+	chunkSize := flag.Int("chunk-size", 0, "Number of entries generated and renamed per chunk with -jsonl, 0 means 1000.")
+	showProgress := flag.Bool("progress", true, "Show a progress bar on stderr while renaming, only when stderr is a terminal.")
 
 	flag.Parse()
 
 	// --- 2. Create Renamer ---
-	reNamer := renamer.NewReNamer()
-	reNamer.SetDryRun(*dryRun)
+	targetPlatform, err := renamer.ParsePlatform(*platform)
+	if err != nil {
+		log.Fatalf("Error parsing -platform: %v", err)
+	}
+	conflictPolicy, err := renamer.ParseConflictPolicy(*conflict)
+	if err != nil {
+		log.Fatalf("Error parsing -conflict: %v", err)
+	}
+	// All file access goes through the renamer filesystem
+	var fsys renamer.FS = renamer.OSFS{}
+	reNamer := renamer.New(
+		renamer.WithFS(fsys),
+		renamer.WithDryRun(*dryRun),
+		renamer.WithRoot(*root),
+		renamer.WithAllowMove(*allowMove),
+		renamer.WithAllowExec(*allowExec),
+		renamer.WithPlatform(targetPlatform),
+		renamer.WithConflictPolicy(conflictPolicy),
+		renamer.WithWorkers(*workers),
+		renamer.WithChunkSize(*chunkSize),
+		renamer.WithParallel(*parallel),
+	)
+	if *showProgress && isTerminal(os.Stderr) {
+		reNamer.SetProgress(progressBar(os.Stderr))
+	}
+
+	// Ctrl+C stops the batch after the entry being renamed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// In JSON Lines mode stdout only carries results, other messages go to stderr
+	var info io.Writer = os.Stdout
+	if *jsonLines {
+		info = os.Stderr
+	}
 
 	// If mapping file is provided, use it directly for renaming
 	if *mappingFile != "" {
+		if *jsonLines {
+			streamMappings(ctx, reNamer, *mappingFile, *outputFile)
+			return
+		}
+
 		// Read mapping file
 		data, err := os.ReadFile(*mappingFile)
 		if err != nil {
@@ -45,7 +114,10 @@ func main() {
 		}
 
 		// Apply mappings
-		results := reNamer.ApplyMapping(mappings, renamer.ModeError)
+		results, err := reNamer.ApplyMappingContext(ctx, mappings, renamer.ModeError)
+		if err != nil {
+			log.Printf("Interrupted: %v\n", err)
+		}
 
 		// Print results
 		resultsJSON, err := json.MarshalIndent(results, "", "    ")
@@ -72,17 +144,13 @@ func main() {
 		if err != nil {
 			log.Fatalf("Error parsing config file %s: %v", *ruleFile, err)
 		}
-		fmt.Printf("Loaded %d rules from config file: %s\n", len(reNamer.Rules), *ruleFile)
+		fmt.Fprintf(info, "Loaded %d rules from config file: %s\n", len(reNamer.Rules), *ruleFile)
 	} else if *ruleJSON != "" {
 		// Load rules from command line (single or multiple)
-		var rules []renamer.Rule
-		if err := json.Unmarshal([]byte(*ruleJSON), &rules); err != nil {
+		if err := reNamer.LoadRule([]byte(*ruleJSON)); err != nil {
 			log.Fatalf("Error parsing -rule JSON: %v", err)
 		}
-		for _, rule := range rules {
-			reNamer.AddRule(rule)
-		}
-		fmt.Printf("Loaded %d rules from command line\n", len(rules))
+		fmt.Fprintf(info, "Loaded %d rules from command line\n", len(reNamer.Rules))
 	}
 
 	if len(reNamer.Rules) == 0 {
@@ -90,68 +158,70 @@ func main() {
 	}
 
 	// --- 3. Get file list ---
-	var filesToProcess []string
-
-	// Process each path (can be file or directory)
-	paths := strings.Split(*path, ",")
-	for _, p := range paths {
-		p = strings.TrimSpace(p)
-		if p == "" {
-			continue
-		}
-
-		fileInfo, err := os.Stat(p)
-		if err != nil {
-			log.Printf("Warning: Cannot access %s: %v\n", p, err)
-			continue
+	source := renamer.NewFileSource()
+	for _, p := range strings.Split(*path, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			source.Paths = append(source.Paths, p)
 		}
+	}
+	source.FS = fsys
+	source.Files = *includeFiles
+	source.Dirs = *includeDirs
+	source.Include = include
+	if *pattern != "*" {
+		source.Include = append([]string{*pattern}, source.Include...)
+	}
+	if *dirPattern != "*" {
+		source.DirInclude = []string{*dirPattern}
+	}
+	source.Exclude = exclude
+	source.IncludeRegexp = includeRegexp
+	source.ExcludeRegexp = excludeRegexp
+	source.MatchPath = *matchPath
+	source.SkipHidden = *skipHidden
+	// Without -recursive only the direct entries of a directory are processed
+	source.MaxDepth = 1
+	if *recursive {
+		source.MaxDepth = *maxDepth
+	}
+	if source.MinSize, err = parseSize(*minSize); err != nil {
+		log.Fatalf("Error parsing -min-size: %v", err)
+	}
+	if source.MaxSize, err = parseSize(*maxSize); err != nil {
+		log.Fatalf("Error parsing -max-size: %v", err)
+	}
+	if source.ModifiedAfter, err = parseTime(*newer); err != nil {
+		log.Fatalf("Error parsing -newer: %v", err)
+	}
+	if source.ModifiedBefore, err = parseTime(*older); err != nil {
+		log.Fatalf("Error parsing -older: %v", err)
+	}
+	source.OnError = func(path string, err error) {
+		log.Printf("Warning: Cannot access %s: %v\n", path, err)
+	}
+	fmt.Fprintf(info, "Processing: %s\n", strings.Join(source.Paths, ", "))
 
-		// Modify directory processing part
-		if fileInfo.IsDir() {
-			// Process directory
-			fmt.Printf("Processing directory: %s (pattern: %s)\n", p, *pattern)
-			// Use filepath.WalkDir instead of os.ReadDir
-			err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
-				if err != nil {
-					log.Printf("Warning: Error accessing %s: %v\n", path, err)
-					return nil // Continue processing other files
-				}
-
-				// If directory and not recursive mode, skip subdirectory
-				if d.IsDir() {
-					if !*recursive && path != p {
-						return filepath.SkipDir
-					}
-					return nil
-				}
-
-				// Process file
-				match, _ := filepath.Match(*pattern, d.Name())
-				if match {
-					filesToProcess = append(filesToProcess, path)
-				}
-				return nil
-			})
+	if *jsonLines {
+		streamFiles(ctx, reNamer, source, *outputFile)
+		return
+	}
 
-			if err != nil {
-				log.Printf("Warning: Error walking directory %s: %v\n", p, err)
-			}
-		} else {
-			// Process single file
-			filesToProcess = append(filesToProcess, p)
-			fmt.Printf("Processing file: %s\n", p)
-		}
+	filesToProcess, err := source.List(ctx)
+	if err != nil {
+		log.Fatalf("Error listing files: %v", err)
 	}
 
 	if len(filesToProcess) == 0 {
-		fmt.Println("No files found to process.")
+		fmt.Fprintln(info, "No files found to process.")
 		return
 	}
 
 	// --- 4. Apply rename rules ---
 	reNamer.AddFiles(filesToProcess)
-	reNamer.SetDryRun(*dryRun)
-	results := reNamer.ApplyBatch()
+	results, err := reNamer.ApplyBatchContext(ctx)
+	if err != nil {
+		log.Printf("Interrupted: %v\n", err)
+	}
 
 	// Print results in JSON format
 	resultsJSON, err := json.MarshalIndent(results, "", "    ")
@@ -171,3 +241,173 @@ func main() {
 		}
 	}
 }
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 30
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressBar returns a progress callback that redraws a single status line
+// on w and ends it once the batch is finished
+func progressBar(w *os.File) renamer.ProgressFunc {
+	return func(p renamer.Progress) {
+		filled := progressBarWidth
+		if p.Planned > 0 {
+			filled = p.Done() * progressBarWidth / p.Planned
+		}
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+		current := p.Current
+		if runes := []rune(current); len(runes) > 40 {
+			current = "..." + string(runes[len(runes)-37:])
+		}
+		fmt.Fprintf(w, "\r\033[K[%s] %d/%d renamed %d failed %d skipped %d %s",
+			bar, p.Done(), p.Planned, p.Renamed, p.Failed, p.Skipped, current)
+		if p.Current == "" {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// jsonLinesWriter opens the JSON Lines output, the file at path or stdout
+func jsonLinesWriter(path string) (*bufio.Writer, func()) {
+	if path == "" {
+		w := bufio.NewWriter(os.Stdout)
+		return w, func() { w.Flush() }
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Error creating output file %s: %v", path, err)
+	}
+	w := bufio.NewWriter(f)
+	return w, func() {
+		if err := w.Flush(); err != nil {
+			log.Printf("Error writing results to file %s: %v\n", path, err)
+		}
+		f.Close()
+	}
+}
+
+// streamFiles renames the files selected by source chunk by chunk and writes
+// every result as one JSON line
+func streamFiles(ctx context.Context, reNamer *renamer.ReNamer, source *renamer.FileSource, outputFile string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	paths, walkErr := source.Channel(ctx)
+
+	w, done := jsonLinesWriter(outputFile)
+	defer done()
+	enc := json.NewEncoder(w)
+	if err := reNamer.Stream(ctx, paths, func(result renamer.ReNameResult) error {
+		return enc.Encode(result)
+	}); err != nil {
+		log.Printf("Interrupted: %v\n", err)
+	}
+	cancel()
+	if err := <-walkErr; err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("Error listing files: %v\n", err)
+	}
+}
+
+// streamMappings executes a JSON Lines mapping file chunk by chunk and writes
+// every result as one JSON line
+func streamMappings(ctx context.Context, reNamer *renamer.ReNamer, mappingFile, outputFile string) {
+	f, err := os.Open(mappingFile)
+	if err != nil {
+		log.Fatalf("Error reading mapping file %s: %v", mappingFile, err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var readErr error
+	mappings := make(chan renamer.ReNameResult)
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		defer close(mappings)
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(strings.TrimSpace(scanner.Text())) == 0 {
+				continue
+			}
+			var mapping renamer.ReNameResult
+			if err := json.Unmarshal(scanner.Bytes(), &mapping); err != nil {
+				readErr = fmt.Errorf("line %d: %v", line, err)
+				return
+			}
+			select {
+			case mappings <- mapping:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = scanner.Err()
+	}()
+
+	w, done := jsonLinesWriter(outputFile)
+	defer done()
+	enc := json.NewEncoder(w)
+	if err := reNamer.StreamMappings(ctx, mappings, renamer.ModeError, func(result renamer.ReNameResult) error {
+		return enc.Encode(result)
+	}); err != nil {
+		log.Printf("Interrupted: %v\n", err)
+	}
+	cancel()
+	<-readDone
+	if readErr != nil {
+		log.Printf("Error parsing mapping file %s: %v\n", mappingFile, readErr)
+	}
+}
+
+// listFlag is a flag that may be given several times
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseSize parses a size in bytes with an optional K, M or G suffix, the
+// empty string means no limit
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	if s == "" {
+		return 0, nil
+	}
+	unit := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		unit, s = 1<<10, strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		unit, s = 1<<20, strings.TrimSuffix(s, "M")
+	case strings.HasSuffix(s, "G"):
+		unit, s = 1<<30, strings.TrimSuffix(s, "G")
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * unit, nil
+}
+
+// parseTime parses a date or an RFC 3339 time, the empty string means no limit
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
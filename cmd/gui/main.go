@@ -1,7 +1,7 @@
 package main
 
 import (
-	"ReNaming/internal/renamer"
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -12,6 +12,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/leotang315/ReNaming/pkg/renamer"
 )
 
 type ReNamerApp struct {
@@ -105,7 +107,7 @@ func (r *ReNamerApp) createToolbar() fyne.CanvasObject {
 func (r *ReNamerApp) createMainContent() fyne.CanvasObject {
 	// 规则列表
 	r.RuleList = widget.NewList(
-		func() int { return len(r.ReNamer.Rules) },
+		func() int { return len(r.ReNamer.GetRules()) },
 		func() fyne.CanvasObject {
 			return container.NewHBox(
 				widget.NewLabel("规则"),
@@ -114,7 +116,11 @@ func (r *ReNamerApp) createMainContent() fyne.CanvasObject {
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
 			items := obj.(*fyne.Container).Objects
-			rule := r.ReNamer.Rules[id]
+			rules := r.ReNamer.GetRules()
+			if id >= len(rules) {
+				return
+			}
+			rule := rules[id]
 			items[0].(*widget.Label).SetText(rule.Name)
 			items[1].(*widget.Label).SetText(fmt.Sprintf("%s -> %s", rule.Pattern, rule.Replace))
 		},
@@ -201,15 +207,31 @@ func (r *ReNamerApp) addFolder() {
 			folderPath = folderPath[1:]
 		}
 
-		// 这里应该遍历文件夹，添加所有文件
-		// 简化版本，实际应用中需要更复杂的处理
-		dialog.ShowInformation("添加文件夹", "已添加文件夹: "+folderPath, r.MainWindow)
+		// 在后台遍历文件夹，跳过隐藏文件和目录，完成后在界面线程中添加
+		source := renamer.NewFileSource(folderPath)
+		source.SkipHidden = true
+		go func() {
+			files, err := source.List(context.Background())
+			fyne.Do(func() {
+				if err != nil {
+					dialog.ShowError(err, r.MainWindow)
+					return
+				}
+				r.Files = append(r.Files, files...)
+				r.ReNamer.AddFiles(files)
+				r.updateStatusBar()
+				r.FileList.Refresh()
+				dialog.ShowInformation("添加文件夹", fmt.Sprintf("已从 %s 添加 %d 个文件", folderPath, len(files)), r.MainWindow)
+			})
+		}()
 	}, r.MainWindow)
 }
 
 func (r *ReNamerApp) showAddRuleDialog() {
 	// 创建规则类型选择
-	ruleTypes := []string{"添加前缀", "添加后缀", "替换文本", "删除文本", "正则替换"}
+	ruleTypes := []string{"添加前缀", "添加后缀", "替换文本", "删除文本", "正则替换", "清理非法字符", "汉字转拼音", "转写为ASCII", "简体转繁体", "繁体转简体", "全角转半角", "整理影视文件名", "表达式"}
+	// 通过 renamer.RegisterRuleKind 注册的自定义规则类型
+	ruleTypes = append(ruleTypes, renamer.RuleKinds()...)
 	ruleTypeSelect := widget.NewSelect(ruleTypes, nil)
 
 	// 创建输入字段
@@ -243,6 +265,29 @@ func (r *ReNamerApp) showAddRuleDialog() {
 					Pattern: patternEntry.Text,
 					Replace: replaceEntry.Text,
 				}
+			case "清理非法字符":
+				rule = ruleFactory.Sanitize(renamer.PlatformPortable, replaceEntry.Text)
+			case "汉字转拼音":
+				rule = ruleFactory.Pinyin(renamer.ToneNone, replaceEntry.Text, false)
+			case "转写为ASCII":
+				rule = ruleFactory.Transliterate(renamer.CyrillicBGN, renamer.KanaHepburn)
+			case "简体转繁体":
+				rule = ruleFactory.ToTraditional()
+			case "繁体转简体":
+				rule = ruleFactory.ToSimplified()
+			case "全角转半角":
+				rule = ruleFactory.ToHalfWidth()
+			case "整理影视文件名":
+				rule = ruleFactory.MediaName(replaceEntry.Text, "")
+			case "表达式":
+				rule = ruleFactory.Expression(replaceEntry.Text)
+			default:
+				rule = renamer.Rule{
+					Name:    ruleTypeSelect.Selected,
+					Type:    ruleTypeSelect.Selected,
+					Pattern: patternEntry.Text,
+					Replace: replaceEntry.Text,
+				}
 			}
 
 			r.ReNamer.AddRule(rule)
@@ -254,35 +299,69 @@ func (r *ReNamerApp) showAddRuleDialog() {
 }
 
 func (r *ReNamerApp) previewRename() {
-	if len(r.Files) == 0 || len(r.ReNamer.Rules) == 0 {
+	if len(r.Files) == 0 || len(r.ReNamer.GetRules()) == 0 {
 		dialog.ShowInformation("提示", "请先添加文件和规则", r.MainWindow)
 		return
 	}
 
-	// 设置为预览模式
-	r.ReNamer.SetDryRun(true)
-
-	// 应用规则
-	results := r.ReNamer.ApplyBatch()
-
-	// 更新UI显示预览结果
-	r.updatePreviewResults(results)
+	// 在后台以预览模式应用规则，完成后更新UI显示预览结果
+	r.runBatch("正在预览", true, r.updatePreviewResults)
 }
 
 func (r *ReNamerApp) executeRename() {
-	if len(r.Files) == 0 || len(r.ReNamer.Rules) == 0 {
+	if len(r.Files) == 0 || len(r.ReNamer.GetRules()) == 0 {
 		dialog.ShowInformation("提示", "请先添加文件和规则", r.MainWindow)
 		return
 	}
 
-	// 设置为实际执行模式
-	r.ReNamer.SetDryRun(false)
-
-	// 应用规则
-	results := r.ReNamer.ApplyBatch()
+	// 在后台以实际执行模式应用规则，完成后更新UI显示结果
+	r.runBatch("正在重命名", false, r.updateRenameResults)
+}
 
-	// 更新UI显示结果
-	r.updateRenameResults(results)
+// runBatch 在后台执行批量重命名并显示可取消的进度对话框，结束后在界面线程中用结果调用 done。
+// 批次使用开始时的规则和文件列表快照，执行期间仍可编辑规则；取消时已处理的结果会保留
+func (r *ReNamerApp) runBatch(title string, dryRun bool, done func(results []renamer.ReNameResult)) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bar := widget.NewProgressBar()
+	counts := widget.NewLabel("正在生成映射...")
+	current := widget.NewLabel("")
+	current.Truncation = fyne.TextTruncateEllipsis
+	progress := dialog.NewCustom(title, "取消", container.NewVBox(bar, counts, current), r.MainWindow)
+	progress.SetOnClosed(cancel)
+	progress.Resize(fyne.NewSize(480, 0))
+	progress.Show()
+
+	r.PreviewBtn.Disable()
+	r.RenameBtn.Disable()
+
+	// 进度回调在执行重命名的 goroutine 中调用，界面只能在 fyne.Do 中更新
+	batch := r.ReNamer.Snapshot(renamer.WithDryRun(dryRun), renamer.WithProgress(func(p renamer.Progress) {
+		fyne.Do(func() {
+			if p.Planned > 0 {
+				bar.SetValue(float64(p.Done()) / float64(p.Planned))
+			}
+			counts.SetText(fmt.Sprintf("%d/%d  成功 %d  失败 %d  跳过 %d", p.Done(), p.Planned, p.Renamed, p.Failed, p.Skipped))
+			current.SetText(p.Current)
+		})
+	}))
+
+	go func() {
+		results, err := batch.ApplyBatchContext(ctx)
+		fyne.Do(func() {
+			progress.Hide()
+			r.PreviewBtn.Enable()
+			r.RenameBtn.Enable()
+
+			if err != nil {
+				dialog.ShowInformation("已取消", "操作已取消，已处理的文件保留当前结果", r.MainWindow)
+				if results == nil {
+					return
+				}
+			}
+			done(results)
+		})
+	}()
 }
 
 func (r *ReNamerApp) updatePreviewResults(results []renamer.ReNameResult) {
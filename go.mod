@@ -1,8 +1,12 @@
-module ReNaming
+module github.com/leotang315/ReNaming
 
-go 1.17
+go 1.19
 
-require github.com/google/uuid v1.6.0
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/sys v0.30.0
+	golang.org/x/text v0.22.0
+)
 
 require (
 	fyne.io/fyne/v2 v2.6.0
@@ -35,7 +39,5 @@ require (
 	github.com/yuin/goldmark v1.7.8 // indirect
 	golang.org/x/image v0.24.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
-	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,91 @@
+package renamer
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:embed data/hans_hant.txt
+var hansHantData string
+
+//go:embed data/hant_hans.txt
+var hantHansData string
+
+// 简繁转换方向
+const (
+	ChineseTraditional = "traditional" // 简体转繁体
+	ChineseSimplified  = "simplified"  // 繁体转简体
+)
+
+// chineseTable 简繁转换表，词组优先于单字
+type chineseTable struct {
+	once      sync.Once
+	data      string
+	entries   map[string]string
+	maxPhrase int // 最长词条的字符数
+}
+
+var (
+	hansHant = &chineseTable{data: hansHantData}
+	hantHans = &chineseTable{data: hantHansData}
+)
+
+// load 解析内置转换表
+func (t *chineseTable) load() {
+	t.entries = make(map[string]string)
+	t.maxPhrase = 1
+	for _, line := range strings.Split(t.data, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		t.entries[parts[0]] = parts[1]
+		if n := len([]rune(parts[0])); n > t.maxPhrase {
+			t.maxPhrase = n
+		}
+	}
+}
+
+// Convert 按最长匹配转换字符串
+func (t *chineseTable) Convert(s string) string {
+	t.once.Do(t.load)
+
+	runes := []rune(s)
+	var sb strings.Builder
+	for i := 0; i < len(runes); {
+		matched := false
+		for n := t.maxPhrase; n > 0; n-- {
+			if i+n > len(runes) {
+				continue
+			}
+			if target, ok := t.entries[string(runes[i:i+n])]; ok {
+				sb.WriteString(target)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteRune(runes[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// applyChinese 简繁转换规则的处理函数
+func applyChinese(r Rule, filename string, ctx *RuleContext) (string, error) {
+	switch to := r.option("to", ChineseSimplified); to {
+	case ChineseTraditional:
+		return hansHant.Convert(filename), nil
+	case ChineseSimplified:
+		return hantHans.Convert(filename), nil
+	default:
+		return filename, fmt.Errorf("未知的简繁转换方向 '%s'", to)
+	}
+}
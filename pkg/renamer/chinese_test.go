@@ -0,0 +1,29 @@
+package renamer
+
+import "testing"
+
+func TestChineseConversion(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"to traditional", rf.ToTraditional(), "简体中文", "簡體中文"},
+		{"to simplified", rf.ToSimplified(), "繁體中文", "繁体中文"},
+		{"other text", rf.ToSimplified(), "abc 123", "abc 123"},
+		{"to half width", rf.ToHalfWidth(), "ＡＢＣ１２３", "ABC123"},
+		{"to full width", rf.ToFullWidth(), "ABC123", "ＡＢＣ１２３"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
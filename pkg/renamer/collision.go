@@ -0,0 +1,139 @@
+package renamer
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// nameIndex caches per-directory facts used to detect colliding names:
+// whether a directory lives on a case-insensitive filesystem and which
+// names it already contains, keyed by their comparison form. It is safe for
+// concurrent use.
+type nameIndex struct {
+	fs              FS
+	mu              sync.Mutex
+	insensitiveDirs map[string]bool
+	entries         map[string]map[string]string
+}
+
+func newNameIndex(fsys FS) *nameIndex {
+	return &nameIndex{
+		fs:              fsys,
+		insensitiveDirs: make(map[string]bool),
+		entries:         make(map[string]map[string]string),
+	}
+}
+
+// insensitive reports whether names in dir are compared case-insensitively
+func (n *nameIndex) insensitive(dir string) bool {
+	dir = filepath.Clean(dir)
+	n.mu.Lock()
+	v, ok := n.insensitiveDirs[dir]
+	n.mu.Unlock()
+	if ok {
+		return v
+	}
+
+	v = n.fs.CaseInsensitive(dir)
+	n.mu.Lock()
+	n.insensitiveDirs[dir] = v
+	n.mu.Unlock()
+	return v
+}
+
+// key returns the form of path used to detect collisions in its directory.
+// Names are compared in NFC so that visually identical names collide, and
+// case-insensitively on case-insensitive filesystems.
+func (n *nameIndex) key(path string) string {
+	path = filepath.Clean(path)
+	return nameKey(path, n.insensitive(filepath.Dir(path)))
+}
+
+// nameKey returns the comparison form of a clean path
+func nameKey(path string, insensitive bool) string {
+	path = norm.NFC.String(path)
+	if insensitive {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// existing returns the name in dir whose comparison form is key, if any.
+// The directory is read on first use while holding the lock, so that
+// concurrent renames cannot leave a stale listing in the cache.
+func (n *nameIndex) existing(dir, key string) (string, bool) {
+	dir = filepath.Clean(dir)
+	insensitive := n.insensitive(dir)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	entries, ok := n.entries[dir]
+	if !ok {
+		entries = make(map[string]string)
+		list, _ := n.fs.ReadDir(dir)
+		for _, e := range list {
+			entries[nameKey(filepath.Join(dir, e.Name()), insensitive)] = e.Name()
+		}
+		n.entries[dir] = entries
+	}
+	name, ok := entries[key]
+	return name, ok
+}
+
+// checkTarget returns an error if newPath, or a name that only differs from
+// it by Unicode normalisation or case, already exists and is not the source
+// itself (which happens for case-only renames on case-insensitive filesystems).
+func (n *nameIndex) checkTarget(oldPath, newPath string) error {
+	if target, err := n.fs.Lstat(newPath); err == nil {
+		source, err := n.fs.Lstat(oldPath)
+		if err == nil && n.fs.SameFile(source, target) {
+			return nil
+		}
+		return newError(ErrTargetExists, newPath, "目标已存在: %s", newPath)
+	}
+
+	dir := filepath.Dir(newPath)
+	existing, ok := n.existing(dir, n.key(newPath))
+	if ok && n.key(newPath) != n.key(oldPath) {
+		return newError(ErrTargetExists, newPath, "目标 %s 与已存在的 %s 冲突", newPath, filepath.Join(dir, existing))
+	}
+	return nil
+}
+
+// renamed updates the cached directory entries after a successful rename
+func (n *nameIndex) renamed(oldPath, newPath string) {
+	oldKey, newKey := n.key(oldPath), n.key(newPath)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if entries, ok := n.entries[filepath.Clean(filepath.Dir(oldPath))]; ok {
+		delete(entries, oldKey)
+	}
+	if entries, ok := n.entries[filepath.Clean(filepath.Dir(newPath))]; ok {
+		entries[newKey] = filepath.Base(newPath)
+	}
+}
+
+// isCaseOnlyChange reports whether oldPath and newPath differ only by case
+func isCaseOnlyChange(oldPath, newPath string) bool {
+	return oldPath != newPath && strings.EqualFold(oldPath, newPath)
+}
+
+// renameCaseOnly performs a case-only rename through a temporary name,
+// because a direct rename is rejected or ignored by case-insensitive
+// filesystems.
+func renameCaseOnly(fsys FS, oldPath, newPath string) error {
+	tmp := filepath.Join(filepath.Dir(oldPath), ".renaming-"+uuid.New().String())
+	if err := fsys.Rename(oldPath, tmp); err != nil {
+		return err
+	}
+	if err := fsys.Rename(tmp, newPath); err != nil {
+		// 尽量恢复原名
+		fsys.Rename(tmp, oldPath)
+		return err
+	}
+	return nil
+}
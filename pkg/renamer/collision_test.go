@@ -0,0 +1,49 @@
+package renamer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCaseOnlyRename(t *testing.T) {
+	tests := []struct {
+		name        string
+		insensitive bool
+		files       []string
+		mappings    [][2]string
+		wantStatus  []ReNameStatus
+		wantTree    []string
+	}{
+		{"case only", true, []string{"/d/readme.txt"}, [][2]string{{"/d/readme.txt", "/d/README.txt"}},
+			[]ReNameStatus{StatusSuccess}, []string{"/", "/d", "/d/README.txt"}},
+		{"target differs in case", true, []string{"/d/a.txt", "/d/b.txt"}, [][2]string{{"/d/a.txt", "/d/B.txt"}},
+			[]ReNameStatus{StatusError}, []string{"/", "/d", "/d/a.txt", "/d/b.txt"}},
+		{"target differs in case, sensitive", false, []string{"/d/a.txt", "/d/b.txt"}, [][2]string{{"/d/a.txt", "/d/B.txt"}},
+			[]ReNameStatus{StatusSuccess}, []string{"/", "/d", "/d/B.txt", "/d/b.txt"}},
+		{"swap case", true, []string{"/d/a", "/d/b"}, [][2]string{{"/d/a", "/d/B"}, {"/d/b", "/d/A"}},
+			[]ReNameStatus{StatusSuccess, StatusSuccess}, []string{"/", "/d", "/d/A", "/d/B"}},
+		{"targets collide", true, []string{"/d/a", "/d/b"}, [][2]string{{"/d/a", "/d/x"}, {"/d/b", "/d/X"}},
+			[]ReNameStatus{StatusSuccess, StatusError}, []string{"/", "/d", "/d/b", "/d/x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, tt.files...)
+			fsys.SetCaseInsensitive(tt.insensitive)
+			var mappings []ReNameResult
+			for _, m := range tt.mappings {
+				mappings = append(mappings, ReNameResult{OldPath: m[0], NewPath: m[1]})
+			}
+			results := New(WithFS(fsys)).ApplyMapping(mappings, ModeNormal)
+			var status []ReNameStatus
+			for _, result := range results {
+				status = append(status, result.Status)
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("status = %v, want %v (%+v)", status, tt.wantStatus, results)
+			}
+			if got := listTree(t, fsys, "/"); !reflect.DeepEqual(got, tt.wantTree) {
+				t.Errorf("tree = %v, want %v", got, tt.wantTree)
+			}
+		})
+	}
+}
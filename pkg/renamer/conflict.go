@@ -0,0 +1,145 @@
+package renamer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictPolicy 决定多个文件使用同一目标或目标已存在时的处理方式
+type ConflictPolicy string
+
+const (
+	ConflictError  ConflictPolicy = ""       // 冲突的映射标记为错误（默认）
+	ConflictSkip   ConflictPolicy = "skip"   // 冲突的映射跳过，不视为错误
+	ConflictSuffix ConflictPolicy = "suffix" // 为冲突的目标追加序号：name (1).ext
+)
+
+// ParseConflictPolicy 解析冲突处理方式，空字符串或 "error" 表示报错
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(strings.ToLower(name)); p {
+	case ConflictError, ConflictSkip, ConflictSuffix:
+		return p, nil
+	case "error":
+		return ConflictError, nil
+	default:
+		return ConflictError, fmt.Errorf("未知的冲突处理方式: %s", name)
+	}
+}
+
+// skip marks the result as skipped because of err
+func (m *ReNameResult) skip(err error) {
+	m.Status = StatusSkipped
+	m.Message = "Skipped: " + err.Error()
+	m.Err = err
+}
+
+// resolveConflicts handles mappings whose targets collide with each other
+// according to the conflict policy. Targets are compared in normalised form,
+// and case-insensitively in directories that live on a case-insensitive
+// filesystem.
+func (r *ReNamer) resolveConflicts(mappings []ReNameResult) {
+	names := newNameIndex(r.filesystem())
+	targets := make(map[string][]int)
+	for i, m := range mappings {
+		if m.Status == StatusError || m.NewPath == "" {
+			continue
+		}
+		key := names.key(m.NewPath)
+		targets[key] = append(targets[key], i)
+	}
+
+	if r.ConflictPolicy == ConflictSuffix {
+		r.suffixConflicts(mappings, names, targets)
+		return
+	}
+
+	for _, indexes := range targets {
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			err := newError(ErrConflict, mappings[i].NewPath, "目标 %s 与同一批次中的其他文件冲突", mappings[i].NewPath)
+			if r.ConflictPolicy == ConflictSkip {
+				mappings[i].skip(err)
+			} else {
+				mappings[i].fail(err)
+			}
+		}
+	}
+}
+
+// suffixConflicts gives every colliding mapping except one a numbered
+// target. An entry that keeps its name wins its target, otherwise the first
+// entry in the batch does. Targets that already exist on disk and are not
+// renamed away by the batch are numbered as well.
+func (r *ReNamer) suffixConflicts(mappings []ReNameResult, names *nameIndex, targets map[string][]int) {
+	sources := make(map[string]bool)
+	for _, m := range mappings {
+		if m.Status != StatusError {
+			sources[names.key(m.OldPath)] = true
+		}
+	}
+	taken := func(path string) bool {
+		key := names.key(path)
+		if _, ok := targets[key]; ok {
+			return true
+		}
+		if sources[key] {
+			return false
+		}
+		_, ok := names.existing(filepath.Dir(path), key)
+		return ok
+	}
+
+	for i := range mappings {
+		m := &mappings[i]
+		if m.Status == StatusError || m.NewPath == "" || m.NewPath == m.OldPath {
+			continue
+		}
+		key := names.key(m.NewPath)
+		if winner(mappings, targets[key]) == i {
+			// 目标只被本文件使用时，仍需检查磁盘上是否已有其他文件
+			if key == names.key(m.OldPath) || sources[key] {
+				continue
+			}
+			if _, exists := names.existing(filepath.Dir(m.NewPath), key); !exists {
+				continue
+			}
+		}
+
+		newPath := numberedPath(m.NewPath, r.isDir(m.OldPath), taken)
+		if err := ValidateName(filepath.Base(newPath), r.Platform); err != nil {
+			m.fail(err)
+			continue
+		}
+		m.NewPath = newPath
+		newKey := names.key(newPath)
+		targets[newKey] = append(targets[newKey], i)
+	}
+}
+
+// winner returns the mapping that keeps a contested target
+func winner(mappings []ReNameResult, indexes []int) int {
+	for _, i := range indexes {
+		if mappings[i].NewPath == mappings[i].OldPath {
+			return i
+		}
+	}
+	return indexes[0]
+}
+
+// numberedPath returns the first "name (n).ext" variant of path that is not taken
+func numberedPath(path string, dir bool, taken func(string) bool) string {
+	ext := ""
+	if !dir {
+		ext = filepath.Ext(path)
+	}
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
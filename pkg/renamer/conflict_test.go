@@ -0,0 +1,68 @@
+package renamer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     ConflictPolicy
+		files      []string
+		existing   []string // 不在批次中的文件
+		wantStatus []ReNameStatus
+		wantNames  []string
+	}{
+		{"error", ConflictError, []string{"/d/a.txt", "/d/b.txt"}, nil,
+			[]ReNameStatus{StatusError, StatusError}, []string{"same.txt", "same.txt"}},
+		{"skip", ConflictSkip, []string{"/d/a.txt", "/d/b.txt"}, nil,
+			[]ReNameStatus{StatusSkipped, StatusSkipped}, []string{"same.txt", "same.txt"}},
+		{"suffix", ConflictSuffix, []string{"/d/a.txt", "/d/b.txt", "/d/c.txt"}, nil,
+			[]ReNameStatus{StatusSuccess, StatusSuccess, StatusSuccess}, []string{"same.txt", "same (1).txt", "same (2).txt"}},
+		{"suffix keeps unchanged names", ConflictSuffix, []string{"/d/a.txt", "/d/same.txt", "/d/same (1).txt"}, nil,
+			[]ReNameStatus{StatusSuccess, StatusSuccess, StatusSuccess}, []string{"same (1).txt", "same.txt", "same (2).txt"}},
+		{"suffix with existing targets", ConflictSuffix, []string{"/d/a.txt"}, []string{"/d/same.txt", "/d/same (1).txt"},
+			[]ReNameStatus{StatusSuccess}, []string{"same (2).txt"}},
+		{"error with existing target", ConflictError, []string{"/d/a.txt"}, []string{"/d/same.txt"},
+			[]ReNameStatus{StatusError}, []string{"same.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, append(tt.files, tt.existing...)...)
+			r := New(WithFS(fsys), WithConflictPolicy(tt.policy), WithRules(Rule{Pattern: `^.*$`, Replace: "same"}))
+			r.AddFiles(tt.files)
+			results := r.ApplyBatch()
+			var status []ReNameStatus
+			var names []string
+			for _, result := range results {
+				status = append(status, result.Status)
+				names = append(names, filepath.Base(result.NewPath))
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) || !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("got %v %q, want %v %q", status, names, tt.wantStatus, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    ConflictPolicy
+		wantErr bool
+	}{
+		{"", ConflictError, false},
+		{"error", ConflictError, false},
+		{"SKIP", ConflictSkip, false},
+		{"suffix", ConflictSuffix, false},
+		{"rename", ConflictError, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseConflictPolicy(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseConflictPolicy(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package renamer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 纯数字日期中日和月的顺序
+const (
+	DateOrderDMY = "dmy" // 15-03-2024
+	DateOrderMDY = "mdy" // 03-15-2024
+)
+
+// defaultDateFormat 默认输出格式
+const defaultDateFormat = "YYYY-MM-DD"
+
+// monthNames 英文月份名称及缩写
+var monthNames = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March,
+	"apr": time.April, "may": time.May, "jun": time.June,
+	"jul": time.July, "aug": time.August, "sep": time.September,
+	"oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// monthPattern 英文月份的全称或缩写，月份两侧不能紧挨其他字母（见 findDates）
+const monthPattern = `(?i:(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec)\.?)`
+
+// dateDetector 一种日期写法的识别方式
+type dateDetector struct {
+	re    *regexp.Regexp
+	parse func(m []string, order string) (year, month, day string)
+}
+
+// dateDetectors 按优先级排列的日期识别方式
+var dateDetectors = []dateDetector{
+	// 2024年3月15日
+	{regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日?`), func(m []string, _ string) (string, string, string) {
+		return m[1], m[2], m[3]
+	}},
+	// 2024-03-15、2024.03.15、2024_3_15
+	{regexp.MustCompile(`(\d{4})[-_./](\d{1,2})[-_./](\d{1,2})`), func(m []string, _ string) (string, string, string) {
+		return m[1], m[2], m[3]
+	}},
+	// 15-03-2024 或 03-15-2024
+	{regexp.MustCompile(`(\d{1,2})[-_./](\d{1,2})[-_./](\d{4})`), func(m []string, order string) (string, string, string) {
+		if order == DateOrderMDY {
+			return m[3], m[1], m[2]
+		}
+		return m[3], m[2], m[1]
+	}},
+	// Mar 15 2024、March 15th, 2024
+	{regexp.MustCompile(monthPattern + `[ _-]?(\d{1,2})(?:st|nd|rd|th)?,?[ _-]?(\d{4})`), func(m []string, _ string) (string, string, string) {
+		return m[3], m[1], m[2]
+	}},
+	// 15 Mar 2024、15-March-2024
+	{regexp.MustCompile(`(\d{1,2})(?:st|nd|rd|th)?[ _-]?` + monthPattern + `[ _-]?(\d{4})`), func(m []string, _ string) (string, string, string) {
+		return m[3], m[2], m[1]
+	}},
+	// 20240315
+	{regexp.MustCompile(`(\d{4})(\d{2})(\d{2})`), func(m []string, _ string) (string, string, string) {
+		return m[1], m[2], m[3]
+	}},
+}
+
+// parseDateParts 将识别出的年月日转换为日期，无效日期返回 false
+func parseDateParts(year, month, day string) (time.Time, bool) {
+	y, err := strconv.Atoi(year)
+	if err != nil || y < 1900 || y > 2099 {
+		return time.Time{}, false
+	}
+
+	m, ok := time.Month(0), false
+	if len(month) >= 3 {
+		m, ok = monthNames[strings.ToLower(month[:3])]
+	}
+	if !ok {
+		v, err := strconv.Atoi(month)
+		if err != nil || v < 1 || v > 12 {
+			return time.Time{}, false
+		}
+		m = time.Month(v)
+	}
+
+	d, err := strconv.Atoi(day)
+	if err != nil || d < 1 {
+		return time.Time{}, false
+	}
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if t.Day() != d {
+		// 超出当月天数，如 2 月 30 日
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// formatDate 按 YYYY、YY、MMMM、MMM、MM、M、DD、D 格式化日期，其他字符原样输出
+func formatDate(t time.Time, layout string) string {
+	tokens := []struct {
+		token string
+		value func() string
+	}{
+		{"YYYY", func() string { return fmt.Sprintf("%04d", t.Year()) }},
+		{"YY", func() string { return fmt.Sprintf("%02d", t.Year()%100) }},
+		{"MMMM", func() string { return t.Month().String() }},
+		{"MMM", func() string { return t.Month().String()[:3] }},
+		{"MM", func() string { return fmt.Sprintf("%02d", int(t.Month())) }},
+		{"M", func() string { return strconv.Itoa(int(t.Month())) }},
+		{"DD", func() string { return fmt.Sprintf("%02d", t.Day()) }},
+		{"D", func() string { return strconv.Itoa(t.Day()) }},
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(layout); {
+		matched := false
+		for _, tok := range tokens {
+			if strings.HasPrefix(layout[i:], tok.token) {
+				sb.WriteString(tok.value())
+				i += len(tok.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteByte(layout[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// dateMatch 文件名中识别出的一个日期
+type dateMatch struct {
+	start, end int
+	date       time.Time
+}
+
+// findDates 找出文件名中所有不重叠的日期，数字两侧不能紧挨其他数字，月份名称两侧不能紧挨其他字母
+func findDates(name, order string) []dateMatch {
+	var found []dateMatch
+	taken := make([]bool, len(name))
+
+	for _, det := range dateDetectors {
+		for _, idx := range det.re.FindAllStringSubmatchIndex(name, -1) {
+			start, end := idx[0], idx[1]
+			if start > 0 && isDigitByte(name[start-1]) || end < len(name) && isDigitByte(name[end]) {
+				continue
+			}
+			if overlaps(taken, start, end) {
+				continue
+			}
+
+			m := make([]string, len(idx)/2)
+			for g := range m {
+				if idx[2*g] >= 0 {
+					m[g] = name[idx[2*g]:idx[2*g+1]]
+				}
+			}
+			if !monthBounded(name, idx) {
+				continue
+			}
+			date, ok := parseDateParts(det.parse(m, order))
+			if !ok {
+				continue
+			}
+
+			for i := start; i < end; i++ {
+				taken[i] = true
+			}
+			found = append(found, dateMatch{start: start, end: end, date: date})
+		}
+	}
+	return found
+}
+
+// monthBounded 判断匹配 idx 中的月份名称两侧是否都不是字母，避免把 Summary、decor 等单词的一部分当作月份
+func monthBounded(name string, idx []int) bool {
+	for g := 2; g < len(idx); g += 2 {
+		start, end := idx[g], idx[g+1]
+		if start < 0 || start == end || !isLetterByte(name[start]) {
+			continue
+		}
+		if start > 0 && isLetterByte(name[start-1]) || end < len(name) && isLetterByte(name[end]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetterByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func isDigitByte(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func overlaps(taken []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if taken[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDate 日期规则的处理函数，将文件名中识别出的日期改写为 format 格式
+func applyDate(r Rule, filename string, ctx *RuleContext) (string, error) {
+	order := r.option("order", DateOrderDMY)
+	if order != DateOrderDMY && order != DateOrderMDY {
+		return filename, fmt.Errorf("未知的日期顺序 '%s'", order)
+	}
+	layout := r.option("format", defaultDateFormat)
+
+	found := findDates(filename, order)
+	if len(found) == 0 {
+		return filename, nil
+	}
+
+	// 按位置从后往前替换，避免影响前面的下标
+	sort.Slice(found, func(i, j int) bool { return found[i].start < found[j].start })
+	for i := len(found) - 1; i >= 0; i-- {
+		m := found[i]
+		filename = filename[:m.start] + formatDate(m.date, layout) + filename[m.end:]
+	}
+	return filename, nil
+}
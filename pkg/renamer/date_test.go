@@ -0,0 +1,35 @@
+package renamer
+
+import "testing"
+
+func TestReformatDate(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"compact", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "IMG_20240315", "IMG_2024-03-15"},
+		{"day first", rf.ReformatDate("YYYY.MM.DD", DateOrderDMY), "scan 15-03-2024", "scan 2024.03.15"},
+		{"month first", rf.ReformatDate("YYYY.MM.DD", DateOrderMDY), "scan 03-15-2024", "scan 2024.03.15"},
+		{"month name", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "Mar 15 2024 notes", "2024-03-15 notes"},
+		{"full month name", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "15th September 2024", "2024-09-15"},
+		{"month inside word", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "Summary 12 2024", "Summary 12 2024"},
+		{"word starting with month", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "decor 5 2021", "decor 5 2021"},
+		{"chinese", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "2024年3月15日会议", "2024-03-15会议"},
+		{"long month", rf.ReformatDate("D MMMM YY", DateOrderDMY), "2024-03-05", "5 March 24"},
+		{"invalid date", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "20241399", "20241399"},
+		{"no date", rf.ReformatDate("YYYY-MM-DD", DateOrderDMY), "notes", "notes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Package renamer 批量重命名库，提供规则、映射生成和安全执行。
+//
+// 基本用法：
+//
+//	r := renamer.New(
+//		renamer.WithDryRun(true),
+//		renamer.WithConflictPolicy(renamer.ConflictSuffix),
+//		renamer.WithRules(renamer.NewRuleFactory().AddPrefix("2024_")),
+//	)
+//	r.AddFiles(files)
+//	results, err := r.ApplyBatchContext(ctx)
+//
+// 失败的条目状态为 StatusError，ReNameResult.Err 可用 errors.Is 与
+// ErrTargetExists、ErrOutsideRoot 等错误分类比较。
+//
+// # 兼容性承诺
+//
+// 本包遵循语义化版本，APIVersion 的主版本号不变时：
+//
+//   - 导出的类型、函数和方法不会删除或改变签名，只会新增；
+//   - 规则 JSON（SaveRule/LoadRule 的格式：id、name、type、pattern、Replace、options 字段）
+//     保持可读，已有字段的含义不变，新增的字段都是可选的；
+//   - 映射 JSON（ReNameResult 的 oldPath、newPath、status、message 字段）保持可读，
+//     status 只会新增取值；
+//   - 已有规则类型的参数和默认值不变。
+//
+// 以小写字母开头的标识符以及 Message 的具体文本不属于兼容性承诺。
+package renamer
+
+// APIVersion 库 API 和 JSON 格式的版本
+const APIVersion = "1.0.0"
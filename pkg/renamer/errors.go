@@ -0,0 +1,59 @@
+package renamer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 错误分类，可通过 errors.Is(result.Err, renamer.ErrTargetExists) 判断失败原因
+var (
+	ErrInvalidPath     = errors.New("invalid path")          // 源路径为空或无效
+	ErrInvalidName     = errors.New("invalid file name")     // 生成的文件名在目标平台上不合法
+	ErrMoveNotAllowed  = errors.New("move not allowed")      // 未允许移动时目标不在原目录
+	ErrOutsideRoot     = errors.New("outside sandbox root")  // 源或目标越出沙箱根目录
+	ErrTargetExists    = errors.New("target already exists") // 目标已存在
+	ErrConflict        = errors.New("conflicting targets")   // 同一批次中有多个文件使用同一目标
+	ErrUnknownRuleType = errors.New("unknown rule type")     // 规则类型未注册
+	ErrRule            = errors.New("rule failed")           // 规则执行失败
+	ErrRenameFailed    = errors.New("rename failed")         // 文件系统重命名失败
+	ErrInvalidMode     = errors.New("invalid mode")          // 无效的操作模式
+	ErrInvalidPattern  = errors.New("invalid pattern")       // 文件来源中的通配符或正则表达式无效
+	ErrExecNotAllowed  = errors.New("exec not allowed")      // 未允许执行外部命令时使用了 exec 规则
+	ErrRegistration    = errors.New("registration failed")   // 自定义规则类型或模板变量的名称无效或重复
+)
+
+// Error 重命名过程中的错误。Error() 返回可读的详细信息，
+// errors.Is 可与上面的错误分类比较，errors.Unwrap 返回底层错误（可能为空）
+type Error struct {
+	Kind error  // 错误分类
+	Path string // 相关路径
+	Msg  string // 详细信息
+	Err  error  // 底层错误
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// Is 判断错误是否属于 target 分类
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError 创建指定分类的错误
+func newError(kind error, path, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Path: path, Msg: fmt.Sprintf(format, args...)}
+}
+
+// wrapError 将 err 归入 kind 分类，已分类的错误保持不变
+func wrapError(kind error, path string, err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Kind: kind, Path: path, Msg: err.Error(), Err: err}
+}
@@ -0,0 +1,256 @@
+package renamer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExecTimeout 外部命令每次调用的默认超时时间
+const defaultExecTimeout = 30 * time.Second
+
+// batchItem 批量规则处理的单个文件名，处理后写回 Name、Err 和 Message
+type batchItem struct {
+	Name    string
+	Ctx     *RuleContext
+	Err     error
+	Message string
+}
+
+// batchTransform 一次处理整批文件名的规则处理函数
+type batchTransform func(r Rule, items []batchItem)
+
+// batchTransforms 需要批量处理的规则类型，生成映射时整批调用一次
+var batchTransforms = map[string]batchTransform{
+	RuleTypeExec: applyExecBatch,
+}
+
+// execRequest 写入外部命令标准输入的 JSON
+type execRequest struct {
+	Options map[string]string `json:"options,omitempty"`
+	Items   []execRequestItem `json:"items"`
+}
+
+type execRequestItem struct {
+	Name  string            `json:"name"`           // 当前文件名
+	Ext   string            `json:"ext"`            // 原文件扩展名（不含点号）
+	Index int               `json:"index"`          // 文件序号，从 1 开始
+	Path  string            `json:"path"`           // 原文件路径
+	Vars  map[string]string `json:"vars,omitempty"` // 命名捕获组等模板变量
+}
+
+// execResponse 外部命令标准输出返回的 JSON，results 与 items 一一对应
+type execResponse struct {
+	Results []execResult `json:"results"`
+}
+
+type execResult struct {
+	Name  string            `json:"name"`
+	Error string            `json:"error,omitempty"` // 单个文件的错误，非空时该文件标记为失败
+	Vars  map[string]string `json:"vars,omitempty"`  // 新增的模板变量，供后续规则引用
+}
+
+// SetAllowExec 设置是否允许 exec 规则执行外部命令
+func (r *ReNamer) SetAllowExec(allow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.AllowExec = allow
+}
+
+// applyExec 外部命令规则的处理函数，单个文件名时按一批处理
+func applyExec(r Rule, filename string, ctx *RuleContext) (string, error) {
+	items := []batchItem{{Name: filename, Ctx: ctx}}
+	applyExecBatch(r, items)
+	return items[0].Name, items[0].Err
+}
+
+// applyExecBatch 将整批文件名以 JSON 写入外部命令的标准输入，从标准输出读取新文件名。
+// command 为可执行文件，args 为以空白分隔的参数，batchSize 限制每次调用的文件数（默认不限制），
+// timeout 为每次调用的超时时间；命令的标准错误输出会出现在结果的 Message 中。
+// 只有通过 WithAllowExec 或 SetAllowExec 允许后才会执行命令，否则整批以 ErrExecNotAllowed 失败
+func applyExecBatch(r Rule, items []batchItem) {
+	fail := func(items []batchItem, err error) {
+		for i := range items {
+			items[i].Err = err
+		}
+	}
+
+	for i := range items {
+		if !items[i].Ctx.allowExec {
+			fail(items, newError(ErrExecNotAllowed, items[i].Ctx.Path, "未允许执行外部命令，请使用 WithAllowExec(true) 或命令行参数 -allow-exec"))
+			return
+		}
+	}
+
+	command := r.option("command", "")
+	if command == "" {
+		fail(items, fmt.Errorf("外部命令规则缺少 command 参数"))
+		return
+	}
+	timeout := defaultExecTimeout
+	if v := r.option("timeout", ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fail(items, fmt.Errorf("无效的超时时间 '%s'", v))
+			return
+		}
+		timeout = d
+	}
+	batchSize := len(items)
+	if v := r.option("batchSize", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			fail(items, fmt.Errorf("无效的批量大小 '%s'", v))
+			return
+		}
+		if n > 0 {
+			batchSize = n
+		}
+	}
+
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		runExec(r, command, timeout, items[start:end])
+	}
+}
+
+// runExec 调用一次外部命令处理 items
+func runExec(r Rule, command string, timeout time.Duration, items []batchItem) {
+	req := execRequest{Options: r.Options, Items: make([]execRequestItem, len(items))}
+	for i, item := range items {
+		req.Items[i] = execRequestItem{
+			Name:  item.Name,
+			Ext:   strings.TrimPrefix(pathExt(item.Ctx), "."),
+			Index: item.Ctx.Index + 1,
+			Path:  item.Ctx.Path,
+			Vars:  item.Ctx.Vars,
+		}
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		setExecError(items, err, "")
+		return
+	}
+
+	batch := items[0].Ctx.context()
+	ctx, cancel := context.WithTimeout(batch, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command, strings.Fields(r.option("args", ""))...)
+	stdout, stderr, err := runCommand(ctx, cmd, input)
+	stderrText := strings.TrimSpace(string(stderr))
+	switch {
+	case batch.Err() != nil:
+		setExecError(items, fmt.Errorf("外部命令已取消: %v", batch.Err()), stderrText)
+		return
+	case ctx.Err() == context.DeadlineExceeded:
+		setExecError(items, fmt.Errorf("外部命令超时（%v）", timeout), stderrText)
+		return
+	case err != nil:
+		setExecError(items, fmt.Errorf("外部命令失败: %v", err), stderrText)
+		return
+	}
+
+	var resp execResponse
+	if err := json.Unmarshal(stdout, &resp); err != nil {
+		setExecError(items, fmt.Errorf("无法解析外部命令的输出: %v", err), stderrText)
+		return
+	}
+	if len(resp.Results) != len(items) {
+		setExecError(items, fmt.Errorf("外部命令返回了 %d 个结果，应为 %d 个", len(resp.Results), len(items)), stderrText)
+		return
+	}
+
+	for i, res := range resp.Results {
+		if res.Error != "" {
+			items[i].Err = fmt.Errorf("%s", res.Error)
+			continue
+		}
+		items[i].Name = res.Name
+		items[i].Message = stderrText
+		for k, v := range res.Vars {
+			items[i].Ctx.setVar(k, v)
+		}
+	}
+}
+
+// runCommand 将 input 写入命令的标准输入，返回标准输出和标准错误输出。
+// ctx 结束时终止进程并关闭管道，继承了管道的子进程不会使调用一直等待
+func runCommand(ctx context.Context, cmd *exec.Cmd, input []byte) ([]byte, []byte, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		stdin.Write(input)
+		stdin.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(&stdout, stdoutPipe)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(&stderr, stderrPipe)
+	}()
+	copied := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(copied)
+	}()
+
+	select {
+	case <-copied:
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		stdin.Close()
+		stdoutPipe.Close()
+		stderrPipe.Close()
+		<-copied
+	}
+	err = cmd.Wait()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// context 返回批次的上下文，未设置时返回不会被取消的上下文
+func (c *RuleContext) context() context.Context {
+	if c.batch == nil {
+		return context.Background()
+	}
+	return c.batch
+}
+
+// setExecError 将整批标记为失败，错误信息附带外部命令的标准错误输出
+func setExecError(items []batchItem, err error, stderr string) {
+	if stderr != "" {
+		err = fmt.Errorf("%v: %s", err, stderr)
+	}
+	for i := range items {
+		items[i].Err = err
+	}
+}
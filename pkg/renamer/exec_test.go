@@ -0,0 +1,105 @@
+package renamer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeScript writes an executable shell script and returns its path
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rename.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyExecBatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 /bin/sh")
+	}
+	tests := []struct {
+		name    string
+		script  string
+		timeout string
+		cancel  time.Duration // 大于 0 时在此之后取消批次
+		want    string
+		wantErr string
+	}{
+		{"success", `cat >/dev/null; echo '{"results":[{"name":"b"}]}'`, "", 0, "b", ""},
+		{"failure", `echo oops >&2; exit 3`, "", 0, "", "oops"},
+		{"timeout", `sleep 10`, "200ms", 0, "", "超时"},
+		{"cancelled batch", `sleep 10`, "", 200 * time.Millisecond, "", "取消"},
+		{"child keeps pipes open", `sleep 10 & echo '{"results":[{"name":"b"}]}'`, "200ms", 0, "", "超时"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel > 0 {
+				time.AfterFunc(tt.cancel, cancel)
+			}
+			rule := Rule{Type: RuleTypeExec, Options: map[string]string{
+				"command": writeScript(t, tt.script),
+				"timeout": tt.timeout,
+			}}
+			items := []batchItem{{Name: "a", Ctx: &RuleContext{Path: "/d/a.txt", fs: NewMemFS(), batch: ctx, allowExec: true}}}
+
+			start := time.Now()
+			applyExecBatch(rule, items)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+			if tt.wantErr != "" {
+				if items[0].Err == nil || !strings.Contains(items[0].Err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", items[0].Err, tt.wantErr)
+				}
+				return
+			}
+			if items[0].Err != nil {
+				t.Fatalf("err = %v", items[0].Err)
+			}
+			if items[0].Name != tt.want {
+				t.Errorf("got %q, want %q", items[0].Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecRequiresAllowExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 /bin/sh")
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	rule := Rule{Type: RuleTypeExec, Options: map[string]string{
+		"command": writeScript(t, `touch `+marker+`; cat >/dev/null; echo '{"results":[{"name":"b"}]}'`),
+	}}
+	for _, allow := range []bool{false, true} {
+		r := New(WithFS(newMemTree(t, "/d/a.txt")), WithDryRun(true), WithRules(rule), WithAllowExec(allow))
+		r.AddFiles([]string{"/d/a.txt"})
+		results, err := r.ApplyBatchContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, statErr := os.Stat(marker)
+		if !allow {
+			if !errors.Is(results[0].Err, ErrExecNotAllowed) {
+				t.Errorf("allow=false: err = %v, want ErrExecNotAllowed", results[0].Err)
+			}
+			if statErr == nil {
+				t.Error("allow=false: command was run")
+			}
+			continue
+		}
+		if results[0].Err != nil || results[0].NewPath != "/d/b.txt" {
+			t.Errorf("allow=true: got %q, %v", results[0].NewPath, results[0].Err)
+		}
+	}
+}
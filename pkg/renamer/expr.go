@@ -0,0 +1,808 @@
+package renamer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 表达式规则的默认限制
+const (
+	defaultExprMaxSteps = 10000
+	defaultExprTimeout  = 100 * time.Millisecond
+
+	exprMaxLength  = 4096 // 字符串值的最大字节数
+	exprStepLength = 64   // 生成字符串时每 64 字节计为一步
+)
+
+// 表达式的 token 类型
+const (
+	tokEOF = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// lexExpr 将表达式拆分为 token
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, exprToken{tokNumber, src[start:i], start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) {
+				c, size := utf8.DecodeRuneInString(src[i:])
+				if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, exprToken{tokIdent, src[start:i], start})
+		case c == '"' || c == '\'':
+			s, n, err := lexString(src[i:], byte(c))
+			if err != nil {
+				return nil, fmt.Errorf("位置 %d: %v", i, err)
+			}
+			tokens = append(tokens, exprToken{tokString, s, i})
+			i += n
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ",", ".", "[", "]", "=", ";"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("位置 %d: 无法识别的字符 %q", i, c)
+			}
+			tokens = append(tokens, exprToken{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{tokEOF, "", len(src)}), nil
+}
+
+// lexString 读取以 quote 包围的字符串，支持 \n、\t、\\ 和引号转义
+func lexString(src string, quote byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case quote:
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i >= len(src) {
+				break
+			}
+			switch src[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(src[i])
+			}
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("字符串没有结束")
+}
+
+// exprNode 表达式语法树节点
+type exprNode interface {
+	eval(env *exprEnv) (interface{}, error)
+}
+
+type (
+	literalNode struct{ value interface{} }
+	identNode   struct{ name string }
+	unaryNode   struct {
+		op      string
+		operand exprNode
+	}
+	binaryNode struct {
+		op          string
+		left, right exprNode
+	}
+	ternaryNode struct{ cond, then, otherwise exprNode }
+	indexNode   struct{ target, key exprNode }
+	callNode    struct {
+		name string
+		args []exprNode
+	}
+	letNode struct {
+		name        string
+		value, body exprNode
+	}
+)
+
+// exprParser 递归下降解析器
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// parseExpr 解析表达式程序：零个或多个 "let 变量 = 表达式;" 后跟结果表达式
+func parseExpr(src string) (exprNode, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	node, err := p.program()
+	if err != nil {
+		return nil, err
+	}
+	p.accept(";")
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("位置 %d: 多余的 '%s'", t.pos, t.text)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept 下一个 token 为指定运算符时消耗它
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("位置 %d: 缺少 '%s'", t.pos, op)
+	}
+	return nil
+}
+
+func (p *exprParser) program() (exprNode, error) {
+	if t := p.peek(); t.kind == tokIdent && t.text == "let" {
+		p.next()
+		name := p.next()
+		if name.kind != tokIdent {
+			return nil, fmt.Errorf("位置 %d: let 后需要变量名", name.pos)
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		body, err := p.program()
+		if err != nil {
+			return nil, err
+		}
+		return letNode{name.text, value, body}, nil
+	}
+	return p.ternary()
+}
+
+func (p *exprParser) ternary() (exprNode, error) {
+	cond, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	then, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return ternaryNode{cond, then, otherwise}, nil
+}
+
+// binaryLevels 二元运算符，按优先级从低到高排列
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) binary(level int) (exprNode, error) {
+	if level == len(binaryLevels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !containsString(binaryLevels[level], t.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{t.text, left, right}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if t := p.peek(); t.kind == tokOp && (t.text == "!" || t.text == "-") {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{t.text, operand}, nil
+	}
+	return p.postfix()
+}
+
+func (p *exprParser) postfix() (exprNode, error) {
+	node, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			field := p.next()
+			if field.kind != tokIdent {
+				return nil, fmt.Errorf("位置 %d: '.' 后需要字段名", field.pos)
+			}
+			node = indexNode{node, literalNode{field.text}}
+		case p.accept("["):
+			key, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = indexNode{node, key}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("位置 %d: 数字 '%s' 超出范围", t.pos, t.text)
+		}
+		return literalNode{n}, nil
+	case tokString:
+		return literalNode{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		}
+		if !p.accept("(") {
+			return identNode{t.text}, nil
+		}
+		var args []exprNode
+		for !p.accept(")") {
+			if len(args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		return callNode{t.text, args}, nil
+	case tokOp:
+		if t.text == "(" {
+			node, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, fmt.Errorf("表达式不完整")
+	}
+	return nil, fmt.Errorf("位置 %d: 意外的 '%s'", t.pos, t.text)
+}
+
+// exprEnv 表达式的执行环境
+type exprEnv struct {
+	name     string // 规则的输入文件名
+	ctx      *RuleContext
+	locals   map[string]interface{}
+	steps    int
+	maxSteps int
+	deadline time.Time
+	info     os.FileInfo
+	statErr  error
+	statted  bool
+}
+
+// step 计数一次求值，超过步数或时间限制时返回错误
+func (e *exprEnv) step() error {
+	return e.charge(1)
+}
+
+// charge 计数 n 步，超过步数或时间限制时返回错误
+func (e *exprEnv) charge(n int) error {
+	e.steps += n
+	if e.steps > e.maxSteps {
+		return fmt.Errorf("表达式超过 %d 步的执行限制", e.maxSteps)
+	}
+	if time.Now().After(e.deadline) {
+		return fmt.Errorf("表达式执行超时")
+	}
+	return nil
+}
+
+// build 检查生成的字符串值的长度，并按长度计入步数
+func (e *exprEnv) build(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	if len(s) > exprMaxLength {
+		return nil, fmt.Errorf("字符串超过 %d 字节的长度限制", exprMaxLength)
+	}
+	if err := e.charge(len(s) / exprStepLength); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// stat 读取原文件的元数据，只读取一次
+func (e *exprEnv) stat() (os.FileInfo, error) {
+	if !e.statted {
+		e.statted = true
+		if e.ctx.Path == "" {
+			e.statErr = fmt.Errorf("没有文件路径，无法读取元数据")
+		} else {
+			e.info, e.statErr = e.ctx.filesystem().Stat(e.ctx.Path)
+		}
+	}
+	return e.info, e.statErr
+}
+
+// variable 查找变量：let 定义的变量、内置变量，最后是规则链中的模板变量
+func (e *exprEnv) variable(name string) (interface{}, error) {
+	if v, ok := e.locals[name]; ok {
+		return v, nil
+	}
+	switch name {
+	case "name":
+		return e.name, nil
+	case "ext":
+		return strings.TrimPrefix(pathExt(e.ctx), "."), nil
+	case "index":
+		return int64(e.ctx.Index + 1), nil
+	case "path":
+		return e.ctx.Path, nil
+	case "dir":
+		if e.ctx.Path == "" {
+			return "", nil
+		}
+		return filepath.Base(filepath.Dir(e.ctx.Path)), nil
+	case "size":
+		info, err := e.stat()
+		if err != nil {
+			return nil, err
+		}
+		return info.Size(), nil
+	case "modtime":
+		info, err := e.stat()
+		if err != nil {
+			return nil, err
+		}
+		return info.ModTime().Format("2006-01-02"), nil
+	case "vars":
+		vars := make(map[string]string, len(e.ctx.Vars))
+		for k, v := range e.ctx.Vars {
+			vars[k] = v
+		}
+		return vars, nil
+	}
+	if v, ok := e.ctx.lookup(name); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("未定义的变量 '%s'", name)
+}
+
+func (n literalNode) eval(env *exprEnv) (interface{}, error) {
+	return n.value, env.step()
+}
+
+func (n identNode) eval(env *exprEnv) (interface{}, error) {
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+	return env.variable(n.name)
+}
+
+func (n letNode) eval(env *exprEnv) (interface{}, error) {
+	value, err := n.value.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if env.locals == nil {
+		env.locals = make(map[string]interface{})
+	}
+	env.locals[n.name] = value
+	return n.body.eval(env)
+}
+
+func (n unaryNode) eval(env *exprEnv) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !truthy(v), nil
+	}
+	i, ok := v.(int64)
+	if !ok {
+		return nil, fmt.Errorf("'-' 只能用于整数")
+	}
+	return -i, nil
+}
+
+func (n ternaryNode) eval(env *exprEnv) (interface{}, error) {
+	cond, err := n.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(cond) {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+func (n binaryNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// 逻辑运算短路求值，返回决定结果的操作数：regex(name, "\d+") || "0"
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return left, nil
+		}
+		return n.right.eval(env)
+	case "||":
+		if truthy(left) {
+			return left, nil
+		}
+		return n.right.eval(env)
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	li, lInt := left.(int64)
+	ri, rInt := right.(int64)
+	if lInt && rInt {
+		switch n.op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/", "%":
+			if ri == 0 {
+				return nil, fmt.Errorf("除数为零")
+			}
+			if n.op == "/" {
+				return li / ri, nil
+			}
+			return li % ri, nil
+		case "<":
+			return li < ri, nil
+		case "<=":
+			return li <= ri, nil
+		case ">":
+			return li > ri, nil
+		case ">=":
+			return li >= ri, nil
+		}
+	}
+
+	ls, rs := toString(left), toString(right)
+	switch n.op {
+	case "+":
+		if len(ls)+len(rs) > exprMaxLength {
+			return nil, fmt.Errorf("字符串超过 %d 字节的长度限制", exprMaxLength)
+		}
+		return env.build(ls + rs)
+	case "<":
+		return ls < rs, nil
+	case "<=":
+		return ls <= rs, nil
+	case ">":
+		return ls > rs, nil
+	case ">=":
+		return ls >= rs, nil
+	}
+	return nil, fmt.Errorf("'%s' 只能用于整数", n.op)
+}
+
+func (n indexNode) eval(env *exprEnv) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	key, err := n.key.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]string:
+		return t[toString(key)], nil
+	case string:
+		i, ok := key.(int64)
+		runes := []rune(t)
+		if !ok {
+			return nil, fmt.Errorf("字符串下标必须是整数")
+		}
+		if i < 0 {
+			i += int64(len(runes))
+		}
+		if i < 0 || i >= int64(len(runes)) {
+			return "", nil
+		}
+		return string(runes[i]), nil
+	}
+	return nil, fmt.Errorf("无法对 %s 取下标", typeName(target))
+}
+
+func (n callNode) eval(env *exprEnv) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := v.(string); ok && len(s) > exprMaxLength {
+			return nil, fmt.Errorf("函数 '%s' 的参数超过 %d 字节的长度限制", n.name, exprMaxLength)
+		}
+		args[i] = v
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+
+	result, err := n.call(args, env.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return env.build(result)
+}
+
+// call 调用函数或模板过滤器
+func (n callNode) call(args []interface{}, ctx *RuleContext) (interface{}, error) {
+	if fn, ok := exprFuncs[n.name]; ok {
+		return fn(args, ctx)
+	}
+	// 模板过滤器同样可以作为函数调用：pad(index, 4)
+	if filter, ok := templateFilters[n.name]; ok {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("函数 '%s' 缺少参数", n.name)
+		}
+		filterArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			filterArgs[i] = toString(a)
+		}
+		if len(filterArgs) < filter.minArgs || len(filterArgs) > filter.maxArgs {
+			return nil, fmt.Errorf("函数 '%s' 的参数个数错误", n.name)
+		}
+		return filter.apply(toString(args[0]), filterArgs, ctx)
+	}
+	return nil, fmt.Errorf("未知的函数 '%s'", n.name)
+}
+
+// exprFunc 表达式中的函数，ctx 为规则的上下文
+type exprFunc func(args []interface{}, ctx *RuleContext) (interface{}, error)
+
+// exprFuncs 表达式中可用的函数（模板过滤器之外）
+var exprFuncs = map[string]exprFunc{
+	"len": func(args []interface{}, _ *RuleContext) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("len 需要 1 个参数")
+		}
+		return int64(utf8.RuneCountInString(toString(args[0]))), nil
+	},
+	"str": func(args []interface{}, _ *RuleContext) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("str 需要 1 个参数")
+		}
+		return toString(args[0]), nil
+	},
+	"int": func(args []interface{}, _ *RuleContext) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("int 需要 1 个参数")
+		}
+		if i, ok := args[0].(int64); ok {
+			return i, nil
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(toString(args[0])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' 不是整数", toString(args[0]))
+		}
+		return i, nil
+	},
+	"contains":   stringPredicate("contains", strings.Contains),
+	"startsWith": stringPredicate("startsWith", strings.HasPrefix),
+	"endsWith":   stringPredicate("endsWith", strings.HasSuffix),
+	"match": func(args []interface{}, ctx *RuleContext) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("match 需要 2 个参数")
+		}
+		re, err := ctx.compile(toString(args[1]))
+		if err != nil {
+			return nil, fmt.Errorf("无效的正则表达式 '%s': %v", toString(args[1]), err)
+		}
+		return re.MatchString(toString(args[0])), nil
+	},
+}
+
+// stringPredicate 包装两个字符串参数的判断函数
+func stringPredicate(name string, fn func(s, sub string) bool) exprFunc {
+	return func(args []interface{}, _ *RuleContext) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s 需要 2 个参数", name)
+		}
+		return fn(toString(args[0]), toString(args[1])), nil
+	}
+}
+
+// truthy 判断值的真假：false、空字符串和 0 为假
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case int64:
+		return t != 0
+	}
+	return v != nil
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	return fmt.Sprint(v)
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "字符串"
+	case int64:
+		return "整数"
+	case bool:
+		return "布尔值"
+	}
+	return "映射"
+}
+
+// applyExpr 表达式规则的处理函数，Replace 为表达式，其结果作为新文件名
+func applyExpr(r Rule, filename string, ctx *RuleContext) (string, error) {
+	maxSteps := defaultExprMaxSteps
+	if v := r.option("maxSteps", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return filename, fmt.Errorf("无效的步数限制 '%s'", v)
+		}
+		maxSteps = n
+	}
+	timeout := defaultExprTimeout
+	if v := r.option("timeout", ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return filename, fmt.Errorf("无效的超时时间 '%s'", v)
+		}
+		timeout = d
+	}
+
+	node, err := parseExpr(r.Replace)
+	if err != nil {
+		return filename, fmt.Errorf("表达式错误: %v", err)
+	}
+	env := &exprEnv{
+		name:     filename,
+		ctx:      ctx,
+		maxSteps: maxSteps,
+		deadline: time.Now().Add(timeout),
+	}
+	result, err := node.eval(env)
+	if err != nil {
+		return filename, fmt.Errorf("表达式错误: %v", err)
+	}
+	return toString(result), nil
+}
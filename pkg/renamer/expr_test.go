@@ -0,0 +1,47 @@
+package renamer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExprRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		options map[string]string
+		want    string
+		wantErr string // substring of the expected error
+	}{
+		{"concatenation", `name + "_" + pad(index, 3)`, nil, "a.txt_001", ""},
+		{"conditional", `len(name) > 3 ? upper(name) : name`, nil, "A.TXT", ""},
+		{"let", `let n = replace(name, ".", "_"); n + n`, nil, "a_txta_txt", ""},
+		{"pad above limit", `pad("", 200000)`, nil, "", "pad"},
+		{"chained replace", `replace(replace(replace(name, "", "xxxxxxxxxxxxxxxx"), "", "yyyyyyyyyyyyyyyy"), "", "zzzzzzzzzzzzzzzz")`, nil, "", "replace"},
+		{"doubling", strings.Repeat(`let name = name + name; `, 30) + `name`, nil, "", "长度限制"},
+		{"step limit", strings.Repeat(`1 + `, 50) + `1`, map[string]string{"maxSteps": "20"}, "", "步"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Type: RuleTypeExpr, Replace: tt.expr, Options: tt.options}
+			start := time.Now()
+			got, err := rule.ApplyContext("a.txt", &RuleContext{Index: 0})
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
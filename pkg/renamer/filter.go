@@ -0,0 +1,250 @@
+package renamer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFilterLength 会增加长度的过滤器（pad、replace）结果的最大字符数
+const maxFilterLength = 4096
+
+// templateFilter 模板过滤器，args 为过滤器名称后以冒号分隔的参数，
+// ctx 为规则的上下文，正则表达式通过它在批次内只编译一次
+type templateFilter struct {
+	minArgs, maxArgs int
+	apply            func(value string, args []string, ctx *RuleContext) (string, error)
+}
+
+// templateFilters 占位符中可用的过滤器，可在 {var|filter:arg|...} 中依次组合
+var templateFilters = map[string]templateFilter{
+	"upper":    {0, 0, filterUpper},
+	"lower":    {0, 0, filterLower},
+	"title":    {0, 0, filterTitle},
+	"trim":     {0, 1, filterTrim},
+	"replace":  {2, 2, filterReplace},
+	"truncate": {1, 1, filterTruncate},
+	"pad":      {1, 2, filterPad},
+	"add":      {1, 1, filterAdd},
+	"slice":    {1, 2, filterSlice},
+	"split":    {2, 2, filterSplit},
+	"regex":    {1, 2, filterRegex},
+	"default":  {1, 1, filterDefault},
+}
+
+// applyFilter 按名称查找并执行过滤器
+func applyFilter(value, spec string, ctx *RuleContext) (string, error) {
+	parts := splitEscaped(spec, ':')
+	name, args := parts[0], parts[1:]
+	filter, ok := templateFilters[name]
+	if !ok {
+		return value, fmt.Errorf("未知的过滤器 '%s'", name)
+	}
+	if len(args) < filter.minArgs || len(args) > filter.maxArgs {
+		return value, fmt.Errorf("过滤器 '%s' 的参数个数错误", name)
+	}
+	return filter.apply(value, args, ctx)
+}
+
+// splitEscaped 按 sep 拆分字符串，\sep 表示字面的分隔符
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == sep {
+			sb.WriteByte(sep)
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, sb.String())
+			sb.Reset()
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return append(parts, sb.String())
+}
+
+// filterInt 解析过滤器的整数参数
+func filterInt(name, arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("过滤器 '%s' 的参数 '%s' 不是整数", name, arg)
+	}
+	return n, nil
+}
+
+func filterUpper(value string, _ []string, _ *RuleContext) (string, error) {
+	return strings.ToUpper(value), nil
+}
+
+func filterLower(value string, _ []string, _ *RuleContext) (string, error) {
+	return strings.ToLower(value), nil
+}
+
+// filterTitle 每个单词首字母大写
+func filterTitle(value string, _ []string, _ *RuleContext) (string, error) {
+	words := strings.Fields(value)
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = strings.ToUpper(string(r)) + w[size:]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// filterTrim 去掉两端的空白，或 trim:chars 去掉两端的指定字符
+func filterTrim(value string, args []string, _ *RuleContext) (string, error) {
+	if len(args) == 0 {
+		return strings.TrimSpace(value), nil
+	}
+	return strings.Trim(value, args[0]), nil
+}
+
+// filterReplace replace:old:new 替换所有 old
+func filterReplace(value string, args []string, _ *RuleContext) (string, error) {
+	old, replacement := args[0], args[1]
+	// 先计算结果长度，避免生成过长的字符串
+	grow := utf8.RuneCountInString(replacement) - utf8.RuneCountInString(old)
+	if grow > 0 {
+		n := utf8.RuneCountInString(value) + strings.Count(value, old)*grow
+		if n > maxFilterLength {
+			return value, fmt.Errorf("过滤器 'replace' 的结果超过 %d 个字符", maxFilterLength)
+		}
+	}
+	return strings.ReplaceAll(value, old, replacement), nil
+}
+
+// filterTruncate truncate:n 截断到最多 n 个字符
+func filterTruncate(value string, args []string, _ *RuleContext) (string, error) {
+	n, err := filterInt("truncate", args[0])
+	if err != nil {
+		return value, err
+	}
+	runes := []rune(value)
+	if n >= 0 && len(runes) > n {
+		value = string(runes[:n])
+	}
+	return value, nil
+}
+
+// filterPad pad:n 在左侧补零到 n 个字符，pad:n:c 使用字符 c 补齐
+func filterPad(value string, args []string, _ *RuleContext) (string, error) {
+	n, err := filterInt("pad", args[0])
+	if err != nil {
+		return value, err
+	}
+	if n > maxFilterLength {
+		return value, fmt.Errorf("过滤器 'pad' 的宽度 %d 超过上限 %d", n, maxFilterLength)
+	}
+	fill := "0"
+	if len(args) > 1 && args[1] != "" {
+		fill = args[1]
+	}
+	missing := n - utf8.RuneCountInString(value)
+	if missing <= 0 {
+		return value, nil
+	}
+	// 与逐次在左侧添加 fill 的结果相同，多字符的 fill 可能使结果超过 n 个字符
+	width := utf8.RuneCountInString(fill)
+	return strings.Repeat(fill, (missing+width-1)/width) + value, nil
+}
+
+// filterAdd add:n 将数值加上 n
+func filterAdd(value string, args []string, _ *RuleContext) (string, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return value, fmt.Errorf("过滤器 'add' 的输入 '%s' 不是整数", value)
+	}
+	n, err := filterInt("add", args[0])
+	if err != nil {
+		return value, err
+	}
+	return strconv.Itoa(v + n), nil
+}
+
+// filterSlice slice:start:end 按字符截取，包含 start 不包含 end，负数从末尾计算
+func filterSlice(value string, args []string, _ *RuleContext) (string, error) {
+	runes := []rune(value)
+	bound := func(arg string, def int) (int, error) {
+		if arg == "" {
+			return def, nil
+		}
+		n, err := filterInt("slice", arg)
+		if err != nil {
+			return 0, err
+		}
+		if n < 0 {
+			n += len(runes)
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n > len(runes) {
+			n = len(runes)
+		}
+		return n, nil
+	}
+
+	start, err := bound(args[0], 0)
+	if err != nil {
+		return value, err
+	}
+	end := len(runes)
+	if len(args) > 1 {
+		if end, err = bound(args[1], len(runes)); err != nil {
+			return value, err
+		}
+	}
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
+
+// filterSplit split:sep:index 按 sep 拆分后取第 index 段（从 0 开始，负数从末尾计算）
+func filterSplit(value string, args []string, _ *RuleContext) (string, error) {
+	i, err := filterInt("split", args[1])
+	if err != nil {
+		return value, err
+	}
+	parts := strings.Split(value, args[0])
+	if i < 0 {
+		i += len(parts)
+	}
+	if i < 0 || i >= len(parts) {
+		return "", nil
+	}
+	return parts[i], nil
+}
+
+// filterRegex regex:expr:group 提取正则表达式匹配的分组，group 默认为 0（整个匹配）
+func filterRegex(value string, args []string, ctx *RuleContext) (string, error) {
+	re, err := ctx.compile(args[0])
+	if err != nil {
+		return value, fmt.Errorf("无效的正则表达式 '%s': %v", args[0], err)
+	}
+	group := 0
+	if len(args) > 1 {
+		if group, err = filterInt("regex", args[1]); err != nil {
+			return value, err
+		}
+	}
+	if group < 0 || group > re.NumSubexp() {
+		return value, fmt.Errorf("正则表达式 '%s' 没有第 %d 个分组", args[0], group)
+	}
+	m := re.FindStringSubmatch(value)
+	if m == nil {
+		return "", nil
+	}
+	return m[group], nil
+}
+
+// filterDefault default:value 值为空时使用默认值
+func filterDefault(value string, args []string, _ *RuleContext) (string, error) {
+	if value == "" {
+		return args[0], nil
+	}
+	return value, nil
+}
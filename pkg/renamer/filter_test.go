@@ -0,0 +1,51 @@
+package renamer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyFilter(t *testing.T) {
+	tests := []struct {
+		value, spec string
+		want        string
+		wantErr     bool
+	}{
+		{"7", "pad:3", "007", false},
+		{"1234", "pad:3", "1234", false},
+		{"7", "pad:4:ab", "abab7", false},
+		{"7", "pad:4096", strings.Repeat("0", 4095) + "7", false},
+		{"7", "pad:100000", "", true},
+		{"a-b-c", "replace:-:_", "a_b_c", false},
+		{"abc", "replace::" + strings.Repeat("x", 2000), "", true},
+		{"hello world", "title", "Hello World", false},
+		{"abcdef", "slice:1:-1", "bcde", false},
+		{"a.b.c", "split:.:-1", "c", false},
+		{"IMG_0042", "regex:\\d+", "0042", false},
+		{"", "default:x", "x", false},
+		{"x", "unknown", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			start := time.Now()
+			got, err := applyFilter(tt.value, tt.spec, nil)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplatePadLimit(t *testing.T) {
+	rule := Rule{Pattern: "^.*$", Replace: "{index|pad:100000}"}
+	if _, err := rule.ApplyContext("a", &RuleContext{Index: 0}); err == nil {
+		t.Fatal("expected an error for a pad width above the limit")
+	}
+}
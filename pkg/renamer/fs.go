@@ -0,0 +1,259 @@
+package renamer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// FS 重命名使用的文件系统操作。映射生成、冲突检测、沙箱检查和执行都通过 FS 访问文件，
+// 通过 WithFS 可以替换为内存文件系统（NewMemFS）或其他存储
+type FS interface {
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	// WalkDir 按 filepath.WalkDir 的语义遍历 root
+	WalkDir(root string, fn fs.WalkDirFunc) error
+	Open(name string) (fs.File, error)
+	Mkdir(name string, perm os.FileMode) error
+	Rename(oldpath, newpath string) error
+	// SameFile 判断两个 FileInfo 是否指向同一文件
+	SameFile(a, b os.FileInfo) bool
+	// CaseInsensitive 判断目录中的文件名是否不区分大小写
+	CaseInsensitive(dir string) bool
+	// EvalSymlinks 返回解析符号链接后的路径
+	EvalSymlinks(path string) (string, error)
+}
+
+// OSFS 直接操作本地文件系统的 FS 实现
+type OSFS struct{}
+
+func (OSFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSFS) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (OSFS) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (OSFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, fn)
+}
+
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (OSFS) Mkdir(name string, perm os.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+func (OSFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (OSFS) SameFile(a, b os.FileInfo) bool {
+	return os.SameFile(a, b)
+}
+
+func (OSFS) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+// caseVolumes caches the case-sensitivity of every volume seen by OSFS
+var caseVolumes sync.Map
+
+// CaseInsensitive detects case-insensitivity by looking up an existing entry
+// of dir with its case swapped; nothing is written. The result is cached per
+// volume. If dir and its ancestors on the same volume contain no names with
+// letters, the default of the platform is returned.
+func (fsys OSFS) CaseInsensitive(dir string) bool {
+	// 目标目录可能尚不存在，使用最近的已存在的上级目录
+	info, err := os.Stat(dir)
+	for err != nil {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return defaultCaseInsensitive()
+		}
+		dir = parent
+		info, err = os.Stat(dir)
+	}
+
+	volume := volumeID(dir, info)
+	if v, ok := caseVolumes.Load(volume); ok {
+		return v.(bool)
+	}
+	for {
+		if insensitive, ok := probeCase(dir); ok {
+			caseVolumes.Store(volume, insensitive)
+			return insensitive
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		if info, err := os.Stat(parent); err != nil || volumeID(parent, info) != volume {
+			break
+		}
+		dir = parent
+	}
+	return defaultCaseInsensitive()
+}
+
+// probeCase looks up the first entry of dir whose name contains letters with
+// its case swapped. ok is false if dir has no such entry.
+func probeCase(dir string) (insensitive, ok bool) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false, false
+	}
+	defer f.Close()
+	for {
+		entries, err := f.ReadDir(64)
+		for _, e := range entries {
+			swapped := swapCase(e.Name())
+			if swapped == e.Name() {
+				continue
+			}
+			a, err := os.Lstat(filepath.Join(dir, e.Name()))
+			if err != nil {
+				continue
+			}
+			b, err := os.Lstat(filepath.Join(dir, swapped))
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return false, true
+				}
+				continue
+			}
+			// 两个名称都存在时，同一文件说明不区分大小写，不同文件说明区分
+			return os.SameFile(a, b), true
+		}
+		if err != nil {
+			return false, false
+		}
+	}
+}
+
+// defaultCaseInsensitive reports whether the default filesystem of the
+// platform ignores case
+func defaultCaseInsensitive() bool {
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
+
+// swapCase inverts the case of every letter in s
+func swapCase(s string) string {
+	return strings.Map(func(c rune) rune {
+		if unicode.IsUpper(c) {
+			return unicode.ToLower(c)
+		}
+		return unicode.ToUpper(c)
+	}, s)
+}
+
+// walkFS walks root in fsys with the semantics of filepath.WalkDir, for
+// filesystems that have no native walk
+func walkFS(fsys FS, root string, fn fs.WalkDirFunc) error {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkFSDir(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func walkFSDir(fsys FS, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if err == filepath.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		if err = fn(path, d, err); err != nil {
+			if err == filepath.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, e := range entries {
+		if err := walkFSDir(fsys, filepath.Join(path, e.Name()), e, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// mkdirAll creates dir and any missing parents in fsys
+func mkdirAll(fsys FS, dir string) error {
+	if info, err := fsys.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+		}
+		return nil
+	}
+	parent := filepath.Dir(dir)
+	if parent != dir {
+		if err := mkdirAll(fsys, parent); err != nil {
+			return err
+		}
+	}
+	if err := fsys.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+// filesystem 返回重命名使用的文件系统，未设置时使用本地文件系统
+func (r *ReNamer) filesystem() FS {
+	if r.fs == nil {
+		return OSFS{}
+	}
+	return r.fs
+}
+
+// filesystem 返回规则执行时使用的文件系统，未设置时使用本地文件系统
+func (c *RuleContext) filesystem() FS {
+	if c.fs == nil {
+		return OSFS{}
+	}
+	return c.fs
+}
+
+// isDir reports whether path refers to an existing directory in fsys
+func isDir(fsys FS, path string) bool {
+	info, err := fsys.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// exists reports whether path names an entry in fsys, without following a
+// final symlink
+func exists(fsys FS, path string) bool {
+	_, err := fsys.Lstat(path)
+	return err == nil
+}
+
+// isDir reports whether path refers to an existing directory in the
+// renamer's filesystem
+func (r *ReNamer) isDir(path string) bool {
+	return isDir(r.filesystem(), path)
+}
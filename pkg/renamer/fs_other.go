@@ -0,0 +1,25 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package renamer
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writable cannot check permissions without writing on this platform, so
+// directories are assumed to be writable and previews may miss permission
+// errors
+func (OSFS) writable(dir string) error {
+	return nil
+}
+
+// volumeID identifies the volume of path by its volume name
+func volumeID(path string, info os.FileInfo) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.VolumeName(abs)
+}
@@ -0,0 +1,41 @@
+package renamer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOSFSCaseInsensitive(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("expected results assume a case-sensitive Linux filesystem")
+	}
+	tests := []struct {
+		name  string
+		files []string
+		dir   string // directory passed to CaseInsensitive, relative to the test root
+	}{
+		{"entry with letters", []string{"Abc.txt"}, "."},
+		{"entries without letters", []string{"123", "456"}, "."},
+		{"empty directory", nil, "."},
+		{"missing directory", []string{"Abc.txt"}, "new/sub"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, file), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if (OSFS{}).CaseInsensitive(filepath.Join(root, tt.dir)) {
+				t.Error("CaseInsensitive() = true on a case-sensitive filesystem")
+			}
+			entries, _ := os.ReadDir(root)
+			if len(entries) != len(tt.files) {
+				t.Errorf("directory contents changed: %v", entries)
+			}
+		})
+	}
+}
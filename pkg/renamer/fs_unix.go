@@ -0,0 +1,27 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package renamer
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// writable reports whether entries can be created in dir, and returns the
+// error a rename would report. It only checks the permissions with access(2),
+// so that previews never write to the directory.
+func (OSFS) writable(dir string) error {
+	return unix.Access(dir, unix.W_OK|unix.X_OK)
+}
+
+// volumeID identifies the volume of path by its device number
+func volumeID(path string, info os.FileInfo) string {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return strconv.FormatUint(uint64(st.Dev), 10)
+	}
+	return ""
+}
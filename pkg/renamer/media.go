@@ -0,0 +1,158 @@
+package renamer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 媒体文件名模板的默认值
+const (
+	defaultEpisodeTemplate = "{show} - S{season}E{episode}"
+	defaultMovieTemplate   = "{title} ({year})"
+)
+
+var (
+	// episodePattern 剧集编号：S01E02、S1E2、1x02，可带多集 S01E02E03
+	episodePattern = regexp.MustCompile(`(?i)(?:^|[. _-]+)(?:S(\d{1,2})[. _-]?E(\d{1,3})(?:[-E]+\d{1,3})*|(\d{1,2})x(\d{2,3}))(?:[. _-]|$)`)
+	// yearPattern 年份，电影名后通常紧跟年份
+	yearPattern = regexp.MustCompile(`[. _(\[-]+((?:19|20)\d{2})(?:[)\]. _-]|$)`)
+	// resolutionPattern 分辨率
+	resolutionPattern = regexp.MustCompile(`(?i)\b(\d{3,4}[pi]|4k|uhd)\b`)
+	// releaseTags 出现在标题之后的常见发布标签，遇到时结束标题
+	releaseTags = regexp.MustCompile(`(?i)[. _-]+(?:\d{3,4}[pi]|4k|uhd|web[. -]?dl|webrip|web|bluray|blu-ray|bdrip|brrip|hdtv|dvdrip|hdrip|remux|x26[45]|h[. ]?26[45]|hevc|proper|repack|extended|uncut|internal)\b`)
+)
+
+// mediaInfo 从媒体文件名中解析出的字段
+type mediaInfo struct {
+	Show       string
+	Season     int
+	Episode    int
+	Title      string // 剧集名（剧集）或片名（电影）
+	Year       string
+	Resolution string
+}
+
+// cleanTitle 将点号、下划线分隔的标题还原为空格分隔
+func cleanTitle(s string) string {
+	s = strings.NewReplacer(".", " ", "_", " ").Replace(s)
+	s = strings.Trim(s, " -[]()")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// parseMedia 解析场景发布风格的剧集或电影文件名
+func parseMedia(name string) (mediaInfo, bool) {
+	var info mediaInfo
+	if m := resolutionPattern.FindStringSubmatch(name); m != nil {
+		info.Resolution = strings.ToLower(m[1])
+	}
+
+	// 剧集：Show.Name.S01E02.Episode.Title.1080p...
+	if loc := episodePattern.FindStringSubmatchIndex(name); loc != nil {
+		seasonStart, seasonEnd, episodeStart, episodeEnd := loc[2], loc[3], loc[4], loc[5]
+		if seasonStart < 0 {
+			seasonStart, seasonEnd, episodeStart, episodeEnd = loc[6], loc[7], loc[8], loc[9]
+		}
+		info.Season, _ = strconv.Atoi(name[seasonStart:seasonEnd])
+		info.Episode, _ = strconv.Atoi(name[episodeStart:episodeEnd])
+
+		show := name[:loc[0]]
+		if m := yearPattern.FindStringSubmatchIndex(show); m != nil && m[1] == len(show) {
+			// Show.Name.2019.S01E02 中的年份属于剧名
+			info.Year = show[m[2]:m[3]]
+			show = show[:m[0]]
+		}
+		info.Show = cleanTitle(show)
+
+		// 保留 episodePattern 吃掉的结尾分隔符，紧跟在编号后的发布标签才能被 releaseTags 识别
+		rest := name[loc[1]:]
+		if loc[1] > 0 && strings.IndexByte(". _-", name[loc[1]-1]) >= 0 {
+			rest = name[loc[1]-1:]
+		}
+		if tag := releaseTags.FindStringIndex(rest); tag != nil {
+			rest = rest[:tag[0]]
+		}
+		info.Title = cleanTitle(rest)
+		return info, info.Show != ""
+	}
+
+	// 电影：Movie.Title.2019.BluRay...，取发布标签之前的最后一个年份
+	head := name
+	if tag := releaseTags.FindStringIndex(name); tag != nil {
+		head = name[:tag[0]]
+	}
+	var year []int
+	// 相邻年份共用分隔符（如 Blade.Runner.2049.2017），逐个从年份末尾继续查找
+	for offset := 0; offset < len(head); {
+		m := yearPattern.FindStringSubmatchIndex(head[offset:])
+		if m == nil {
+			break
+		}
+		if offset+m[0] > 0 {
+			year = []int{offset + m[0], offset + m[1], offset + m[2], offset + m[3]}
+		}
+		offset += m[3]
+	}
+	if year != nil {
+		info.Title = cleanTitle(name[:year[0]])
+		info.Year = name[year[2]:year[3]]
+		return info, info.Title != ""
+	}
+	return info, false
+}
+
+// fields 返回模板可用的字段值
+func (m mediaInfo) fields() map[string]string {
+	fields := map[string]string{
+		"show":       m.Show,
+		"title":      m.Title,
+		"year":       m.Year,
+		"resolution": m.Resolution,
+	}
+	if m.Show != "" {
+		fields["season"] = fmt.Sprintf("%02d", m.Season)
+		fields["episode"] = fmt.Sprintf("%02d", m.Episode)
+	}
+	return fields
+}
+
+// fillMediaTemplate 将解析出的字段保存为模板变量并填充模板中的 {field} 占位符，
+// 空字段两侧多余的分隔符会被清理
+func fillMediaTemplate(template string, info mediaInfo, ctx *RuleContext) (string, error) {
+	for key, value := range info.fields() {
+		ctx.setVar(key, value)
+	}
+	result, err := expandTemplate(template, ctx, false)
+	if err != nil {
+		return "", err
+	}
+	result = strings.ReplaceAll(result, "()", "")
+	result = strings.ReplaceAll(result, "[]", "")
+	return strings.Trim(strings.Join(strings.Fields(result), " "), " -."), nil
+}
+
+// applyMedia 媒体文件名规则的处理函数。剧集使用 Replace 模板（默认 "{show} - S{season}E{episode}"），
+// 电影使用 movie 参数模板（默认 "{title} ({year})"）；无法识别或模板结果为空时保持原名
+func applyMedia(r Rule, filename string, ctx *RuleContext) (string, error) {
+	info, ok := parseMedia(filename)
+	if !ok {
+		return filename, nil
+	}
+
+	var template string
+	if info.Show != "" {
+		template = r.Replace
+		if template == "" {
+			template = defaultEpisodeTemplate
+		}
+	} else {
+		template = r.option("movie", defaultMovieTemplate)
+	}
+	result, err := fillMediaTemplate(template, info, ctx)
+	if err != nil || result == "" {
+		// 模板引用的字段都为空时保持原名
+		return filename, err
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package renamer
+
+import "testing"
+
+func TestMediaName(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"episode", rf.MediaName("", ""), "The.Office.S02E05.720p.WEB-DL.x264", "The Office - S02E05"},
+		{"episode nxm", rf.MediaName("", ""), "Lost 1x04 HDTV", "Lost - S01E04"},
+		{"episode title", rf.MediaName("{show} - {title}", ""), "Show.Name.S01E02.Pilot.1080p.WEB-DL", "Show Name - Pilot"},
+		{"no episode title", rf.MediaName("{show} - {title}", ""), "Show.Name.S01E02.1080p.WEB-DL.x264-GRP", "Show Name"},
+		{"no episode title before tag", rf.MediaName("{title} ({year})", ""), "Show.Name.S01E02.1080p.WEB-DL.x264-GRP", "Show.Name.S01E02.1080p.WEB-DL.x264-GRP"},
+		{"movie", rf.MediaName("", ""), "Inception.2010.1080p.BluRay.x264", "Inception (2010)"},
+		{"templates", rf.MediaName("{show} {season}x{episode} [{resolution}]", ""), "The.Office.S02E05.720p", "The Office 02x05 [720p]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package renamer
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// MemFS 内存中的文件系统，用于模拟批量重命名、在虚拟目录树上测试规则。
+// 路径按绝对路径处理，相对路径视为相对于根目录；不支持符号链接。
+// 错误与本地文件系统相同，使用 syscall 的错误码，可用 errors.Is 与 fs.ErrNotExist 等比较
+type MemFS struct {
+	mu              sync.RWMutex
+	root            *memNode
+	caseInsensitive bool
+}
+
+// memNode 内存文件系统中的文件或目录
+type memNode struct {
+	name     string
+	dir      bool
+	mode     os.FileMode
+	modTime  time.Time
+	data     []byte
+	children map[string]*memNode
+	parent   *memNode
+}
+
+// NewMemFS 创建只含根目录的内存文件系统
+func NewMemFS() *MemFS {
+	return &MemFS{root: &memNode{
+		name:     "/",
+		dir:      true,
+		mode:     fs.ModeDir | 0755,
+		modTime:  time.Now(),
+		children: make(map[string]*memNode),
+	}}
+}
+
+// SetCaseInsensitive 设置文件名是否不区分大小写，用于模拟 Windows 和 macOS 的默认文件系统
+func (m *MemFS) SetCaseInsensitive(insensitive bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.caseInsensitive = insensitive
+}
+
+// splitPath 将路径拆分为各级名称
+func splitPath(name string) []string {
+	name = filepath.ToSlash(filepath.Clean(name))
+	name = strings.TrimPrefix(name, filepath.ToSlash(filepath.VolumeName(name)))
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// child 查找目录中的条目，不区分大小写时按大小写折叠比较
+func (m *MemFS) child(dir *memNode, name string) *memNode {
+	if n, ok := dir.children[name]; ok {
+		return n
+	}
+	if m.caseInsensitive {
+		for key, n := range dir.children {
+			if strings.EqualFold(key, name) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// lookup 查找路径对应的节点
+func (m *MemFS) lookup(op, name string) (*memNode, error) {
+	n := m.root
+	for _, part := range splitPath(name) {
+		if !n.dir {
+			return nil, &os.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+		}
+		if part == ".." {
+			if n.parent != nil {
+				n = n.parent
+			}
+			continue
+		}
+		if n = m.child(n, part); n == nil {
+			return nil, &os.PathError{Op: op, Path: name, Err: syscall.ENOENT}
+		}
+	}
+	return n, nil
+}
+
+// lookupParent 查找路径的父目录和最后一级名称
+func (m *MemFS) lookupParent(op, name string) (*memNode, string, error) {
+	parent, err := m.lookup(op, filepath.Dir(filepath.Clean(name)))
+	if err != nil {
+		return nil, "", err
+	}
+	if !parent.dir {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+	}
+	base := filepath.Base(filepath.Clean(name))
+	if base == "/" || base == "." || base == ".." || base == string(filepath.Separator) {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: syscall.EINVAL}
+	}
+	return parent, base, nil
+}
+
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info(), nil
+}
+
+func (m *MemFS) Lstat(name string) (os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, err := m.lookup("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info(), nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.dir {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: syscall.ENOTDIR}
+	}
+	return n.entries(), nil
+}
+
+// WalkDir 按 filepath.WalkDir 的语义遍历 root，目录内按名称排序
+func (m *MemFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return walkFS(m, root, fn)
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{info: n.info(), reader: bytes.NewReader(append([]byte(nil), n.data...)), dir: n.dir}, nil
+}
+
+func (m *MemFS) Mkdir(name string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	parent, base, err := m.lookupParent("mkdir", name)
+	if err != nil {
+		return err
+	}
+	if m.child(parent, base) != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: syscall.EEXIST}
+	}
+	parent.children[base] = &memNode{
+		name:     base,
+		dir:      true,
+		mode:     fs.ModeDir | perm.Perm(),
+		modTime:  time.Now(),
+		children: make(map[string]*memNode),
+		parent:   parent,
+	}
+	return nil
+}
+
+// MkdirAll 创建目录及所有缺失的上级目录
+func (m *MemFS) MkdirAll(name string) error {
+	return mkdirAll(m, name)
+}
+
+// WriteFile 创建或覆盖文件，上级目录必须已存在
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	parent, base, err := m.lookupParent("open", name)
+	if err != nil {
+		return err
+	}
+	if n := m.child(parent, base); n != nil {
+		if n.dir {
+			return &os.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+		}
+		n.data = append([]byte(nil), data...)
+		n.modTime = time.Now()
+		return nil
+	}
+	parent.children[base] = &memNode{
+		name:    base,
+		mode:    0644,
+		modTime: time.Now(),
+		data:    append([]byte(nil), data...),
+		parent:  parent,
+	}
+	return nil
+}
+
+// Rename 按 POSIX 语义重命名：目标为文件时被覆盖，目标为非空目录时失败
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+
+	src, err := m.lookup("rename", oldpath)
+	if err != nil {
+		return linkErr(underlying(err))
+	}
+	if src == m.root {
+		return linkErr(syscall.EBUSY)
+	}
+	parent, base, err := m.lookupParent("rename", newpath)
+	if err != nil {
+		return linkErr(underlying(err))
+	}
+	// 不能把目录移动到自身内部
+	for p := parent; p != nil; p = p.parent {
+		if p == src {
+			return linkErr(syscall.EINVAL)
+		}
+	}
+
+	if dst := m.child(parent, base); dst != nil && dst != src {
+		switch {
+		case src.dir && !dst.dir:
+			return linkErr(syscall.ENOTDIR)
+		case !src.dir && dst.dir:
+			return linkErr(syscall.EISDIR)
+		case dst.dir && len(dst.children) > 0:
+			return linkErr(syscall.ENOTEMPTY)
+		}
+		delete(parent.children, dst.name)
+	}
+
+	delete(src.parent.children, src.name)
+	src.name = base
+	src.parent = parent
+	parent.children[base] = src
+	return nil
+}
+
+func (m *MemFS) SameFile(a, b os.FileInfo) bool {
+	ai, ok := a.(*memInfo)
+	if !ok {
+		return false
+	}
+	bi, ok := b.(*memInfo)
+	return ok && ai.node == bi.node
+}
+
+func (m *MemFS) CaseInsensitive(dir string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.caseInsensitive
+}
+
+// EvalSymlinks 内存文件系统没有符号链接，返回路径的规范形式
+func (m *MemFS) EvalSymlinks(path string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, err := m.lookup("lstat", path)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for ; n != m.root; n = n.parent {
+		parts = append([]string{n.name}, parts...)
+	}
+	return string(filepath.Separator) + filepath.Join(parts...), nil
+}
+
+// info 返回节点的 FileInfo
+func (n *memNode) info() os.FileInfo {
+	return &memInfo{
+		node:    n,
+		name:    n.name,
+		size:    int64(len(n.data)),
+		mode:    n.mode,
+		modTime: n.modTime,
+	}
+}
+
+// entries 返回按名称排序的目录条目
+func (n *memNode) entries() []os.DirEntry {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := make([]os.DirEntry, len(names))
+	for i, name := range names {
+		entries[i] = fs.FileInfoToDirEntry(n.children[name].info())
+	}
+	return entries
+}
+
+// memInfo 内存文件系统的 FileInfo，保存读取时的快照
+type memInfo struct {
+	node    *memNode
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (i *memInfo) Name() string       { return i.name }
+func (i *memInfo) Size() int64        { return i.size }
+func (i *memInfo) Mode() os.FileMode  { return i.mode }
+func (i *memInfo) ModTime() time.Time { return i.modTime }
+func (i *memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memInfo) Sys() interface{}   { return nil }
+
+// memFile 打开的内存文件，读取的是打开时的内容
+type memFile struct {
+	info   os.FileInfo
+	reader *bytes.Reader
+	dir    bool
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.dir {
+		return 0, &os.PathError{Op: "read", Path: f.info.Name(), Err: syscall.EISDIR}
+	}
+	return f.reader.Read(p)
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
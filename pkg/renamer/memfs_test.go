@@ -0,0 +1,63 @@
+package renamer
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestMemFSRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		err      error // nil means the rename succeeds
+	}{
+		{"file", "/d/a", "/d/b", nil},
+		{"into other directory", "/d/a", "/e/a", nil},
+		{"directory", "/e", "/f", nil},
+		{"over file", "/d/a", "/d/c", nil},
+		{"missing source", "/d/x", "/d/y", fs.ErrNotExist},
+		{"missing target directory", "/d/a", "/x/a", fs.ErrNotExist},
+		{"target parent is a file", "/d/a", "/d/c/a", syscall.ENOTDIR},
+		{"directory into itself", "/e", "/e/sub/e", syscall.EINVAL},
+		{"directory over file", "/e", "/d/c", syscall.ENOTDIR},
+		{"file over directory", "/d/a", "/e", syscall.EISDIR},
+		{"root", "/", "/r", syscall.EBUSY},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, "/d/a", "/d/c", "/e/sub/f")
+			err := fsys.Rename(tt.old, tt.new)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("Rename: %v", err)
+				}
+				if _, err := fsys.Stat(tt.old); !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("source still exists: %v", err)
+				}
+				if _, err := fsys.Stat(tt.new); err != nil {
+					t.Errorf("target missing: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMemFSCaseInsensitive(t *testing.T) {
+	fsys := newMemTree(t, "/d/File.txt")
+	if _, err := fsys.Stat("/d/file.txt"); err == nil {
+		t.Fatal("case-sensitive MemFS found a name with different case")
+	}
+	fsys.SetCaseInsensitive(true)
+	if _, err := fsys.Stat("/d/FILE.TXT"); err != nil {
+		t.Fatalf("case-insensitive MemFS: %v", err)
+	}
+	if !fsys.CaseInsensitive("/d") {
+		t.Error("CaseInsensitive() = false")
+	}
+}
@@ -0,0 +1,26 @@
+package renamer
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normForms 支持的 Unicode 规范化形式
+var normForms = map[string]norm.Form{
+	"NFC":  norm.NFC,
+	"NFD":  norm.NFD,
+	"NFKC": norm.NFKC,
+	"NFKD": norm.NFKD,
+}
+
+// applyNormalize Unicode 规范化规则的处理函数，默认转换为 NFC
+func applyNormalize(r Rule, filename string, ctx *RuleContext) (string, error) {
+	name := strings.ToUpper(r.option("form", "NFC"))
+	form, ok := normForms[name]
+	if !ok {
+		return filename, fmt.Errorf("未知的规范化形式 '%s'", name)
+	}
+	return form.String(filename), nil
+}
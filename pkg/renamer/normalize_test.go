@@ -0,0 +1,28 @@
+package renamer
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"nfc", rf.Normalize("NFC"), "Café", "Café"},
+		{"nfd", rf.Normalize("NFD"), "Café", "Café"},
+		{"nfkc", rf.Normalize("NFKC"), "Ａ①", "A1"},
+		{"already normalized", rf.Normalize("NFC"), "plain", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
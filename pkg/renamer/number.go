@@ -0,0 +1,174 @@
+package renamer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 数字规则的处理方式
+const (
+	NumberPad      = "pad"      // 补零到指定位数：ep1 → ep01
+	NumberUnpad    = "unpad"    // 去掉前导零：ep001 → ep1
+	NumberOffset   = "offset"   // 加上偏移量：page7 → page17
+	NumberRenumber = "renumber" // 按文件序号重新编号
+)
+
+// defaultNumberPattern 默认匹配文件名中的数字
+const defaultNumberPattern = `\d+`
+
+// numberOptions 数字规则的参数
+type numberOptions struct {
+	mode   string
+	width  int // 最小位数，为 -1 时保持原数字的位数
+	offset int64
+	start  int64
+	step   int64
+	which  string // all、first、last 或从 1 开始的序号
+}
+
+// parseNumberOptions 解析数字规则的参数
+func parseNumberOptions(r Rule) (numberOptions, error) {
+	opts := numberOptions{
+		mode:  r.option("mode", NumberPad),
+		width: -1,
+		which: r.option("which", "all"),
+	}
+
+	var err error
+	if v := r.option("width", ""); v != "" {
+		if opts.width, err = strconv.Atoi(v); err != nil || opts.width < 0 {
+			return opts, fmt.Errorf("无效的位数 '%s'", v)
+		}
+	}
+	if opts.offset, err = strconv.ParseInt(r.option("offset", "0"), 10, 64); err != nil {
+		return opts, fmt.Errorf("无效的偏移量 '%s'", r.option("offset", ""))
+	}
+	if opts.start, err = strconv.ParseInt(r.option("start", "1"), 10, 64); err != nil {
+		return opts, fmt.Errorf("无效的起始值 '%s'", r.option("start", ""))
+	}
+	if opts.step, err = strconv.ParseInt(r.option("step", "1"), 10, 64); err != nil {
+		return opts, fmt.Errorf("无效的步长 '%s'", r.option("step", ""))
+	}
+
+	switch opts.mode {
+	case NumberPad, NumberUnpad, NumberOffset, NumberRenumber:
+	default:
+		return opts, fmt.Errorf("未知的数字处理方式 '%s'", opts.mode)
+	}
+	if opts.mode == NumberPad && opts.width < 0 {
+		return opts, fmt.Errorf("补零需要指定位数")
+	}
+	return opts, nil
+}
+
+// selected 判断第 n 个（从 0 开始，共 total 个）匹配是否需要处理
+func (o numberOptions) selected(n, total int) (bool, error) {
+	switch o.which {
+	case "all":
+		return true, nil
+	case "first":
+		return n == 0, nil
+	case "last":
+		return n == total-1, nil
+	}
+	k, err := strconv.Atoi(o.which)
+	if err != nil || k < 1 {
+		return false, fmt.Errorf("无效的数字位置 '%s'", o.which)
+	}
+	return n == k-1, nil
+}
+
+// convert 按处理方式转换一个数字字符串
+func (o numberOptions) convert(digits string, ctx *RuleContext) (string, error) {
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return digits, fmt.Errorf("数字 '%s' 超出范围", digits)
+	}
+
+	width := o.width
+	switch o.mode {
+	case NumberPad:
+		// 补零不会缩短已有的位数
+		if len(digits) > width {
+			width = len(digits)
+		}
+	case NumberUnpad:
+		width = 0
+	case NumberOffset:
+		value += o.offset
+		if width < 0 {
+			width = len(digits)
+		}
+	case NumberRenumber:
+		value = o.start + int64(ctx.Index)*o.step
+		if width < 0 {
+			width = len(digits)
+		}
+	}
+
+	if value < 0 {
+		return digits, fmt.Errorf("数字 '%s' 处理后为负数 %d", digits, value)
+	}
+	return fmt.Sprintf("%0*d", width, value), nil
+}
+
+// applyNumber 数字规则的处理函数。Pattern 用于定位数字，含捕获组时处理第一个捕获组，
+// 为空时匹配文件名中的所有数字
+func applyNumber(r Rule, filename string, ctx *RuleContext) (string, error) {
+	opts, err := parseNumberOptions(r)
+	if err != nil {
+		return filename, err
+	}
+
+	pattern := r.Pattern
+	if pattern == "" {
+		pattern = defaultNumberPattern
+	}
+	re, err := ctx.compile(pattern)
+	if err != nil {
+		return filename, fmt.Errorf("无效的正则表达式 '%s': %v", pattern, err)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(filename, -1)
+	var sb strings.Builder
+	last := 0
+	for n, m := range matches {
+		ok, err := opts.selected(n, len(matches))
+		if err != nil {
+			return filename, err
+		}
+
+		// 有捕获组时处理第一个捕获组
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		if !ok || !isDigits(filename[start:end]) {
+			continue
+		}
+
+		converted, err := opts.convert(filename[start:end], ctx)
+		if err != nil {
+			return filename, err
+		}
+		sb.WriteString(filename[last:start])
+		sb.WriteString(converted)
+		last = end
+	}
+	sb.WriteString(filename[last:])
+	return sb.String(), nil
+}
+
+// isDigits 判断字符串是否全部由 ASCII 数字组成
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
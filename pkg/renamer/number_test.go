@@ -0,0 +1,48 @@
+package renamer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestNumberRule(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"pad", rf.PadNumbers(3), "ep1 part12", "ep001 part012"},
+		{"pad wider", rf.PadNumbers(2), "ep123", "ep123"},
+		{"unpad", rf.UnpadNumbers(), "ep001 part000", "ep1 part0"},
+		{"offset", rf.OffsetNumbers(10), "page7", "page17"},
+		{"offset keeps width", rf.OffsetNumbers(1), "page007", "page008"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenumberNumbers(t *testing.T) {
+	files := []string{"/d/img_9.jpg", "/d/img_4.jpg", "/d/img_7.jpg"}
+	r := New(WithFS(newMemTree(t, files...)), WithRules(NewRuleFactory().RenumberNumbers("", 10, 5, 3)))
+	mappings, err := r.generateMappings(context.Background(), files, 0)
+	if err != nil {
+		t.Fatalf("generateMappings: %v", err)
+	}
+	want := []string{"img_010.jpg", "img_015.jpg", "img_020.jpg"}
+	for i, m := range mappings {
+		if got := filepath.Base(m.NewPath); got != want[i] {
+			t.Errorf("%s: got %q, want %q (%s)", m.OldPath, got, want[i], m.Message)
+		}
+	}
+}
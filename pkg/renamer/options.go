@@ -0,0 +1,92 @@
+package renamer
+
+// Option 创建 ReNamer 时的配置项
+type Option func(r *ReNamer)
+
+// New 使用默认配置和给定的配置项创建 ReNamer
+func New(opts ...Option) *ReNamer {
+	r := NewReNamer()
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithDryRun 设置预览模式，在内存覆盖层上模拟执行，不修改文件
+func WithDryRun(dryRun bool) Option {
+	return func(r *ReNamer) {
+		r.DryRun = dryRun
+	}
+}
+
+// WithConflictPolicy 设置目标冲突时的处理方式
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(r *ReNamer) {
+		r.ConflictPolicy = policy
+	}
+}
+
+// WithFS 设置执行重命名使用的文件系统
+func WithFS(fsys FS) Option {
+	return func(r *ReNamer) {
+		r.fs = fsys
+	}
+}
+
+// WithRules 设置重命名规则
+func WithRules(rules ...Rule) Option {
+	return func(r *ReNamer) {
+		for _, rule := range rules {
+			r.AddRule(rule)
+		}
+	}
+}
+
+// WithRoot 设置沙箱根目录
+func WithRoot(root string) Option {
+	return func(r *ReNamer) {
+		r.Root = root
+	}
+}
+
+// WithAllowMove 设置是否允许将文件移动到其他目录
+func WithAllowMove(allow bool) Option {
+	return func(r *ReNamer) {
+		r.AllowMove = allow
+	}
+}
+
+// WithAllowExec 设置是否允许 exec 规则执行外部命令，默认不允许
+func WithAllowExec(allow bool) Option {
+	return func(r *ReNamer) {
+		r.AllowExec = allow
+	}
+}
+
+// WithPlatform 设置校验文件名时使用的目标平台
+func WithPlatform(platform Platform) Option {
+	return func(r *ReNamer) {
+		r.Platform = platform
+	}
+}
+
+// WithProcessExtension 设置是否处理文件扩展名
+func WithProcessExtension(process bool) Option {
+	return func(r *ReNamer) {
+		r.ProcessExtension = process
+	}
+}
+
+// SetConflictPolicy 设置目标冲突时的处理方式
+func (r *ReNamer) SetConflictPolicy(policy ConflictPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ConflictPolicy = policy
+}
+
+// SetFS 设置执行重命名使用的文件系统
+func (r *ReNamer) SetFS(fsys FS) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fs = fsys
+}
@@ -0,0 +1,480 @@
+package renamer
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxSymlinks 解析路径时最多跟随的符号链接数
+const maxSymlinks = 40
+
+// overlayFS 在 base 之上记录修改而不改动 base。预览模式在覆盖层上按实际流程执行重命名，
+// 得到与实际执行相同的状态和消息
+type overlayFS struct {
+	base  FS
+	mu    sync.Mutex
+	roots map[string]*overlayNode // 按卷名区分的根目录
+}
+
+// overlayNode 覆盖层中的文件或目录。来自 base 的条目记录其在 base 中的原始路径，
+// 目录的子条目在第一次访问时从 base 读取。compact 之后未读取的目录只保留与 base 不同的部分：
+// children 为移入或新建的条目，removed 为已移走的 base 条目
+type overlayNode struct {
+	name     string
+	basePath string      // base 中的路径，覆盖层中新建的条目为空
+	info     os.FileInfo // 覆盖层中新建的条目的信息
+	dir      bool
+	symlink  bool
+	loaded   bool
+	children map[string]*overlayNode
+	removed  map[string]bool
+	parent   *overlayNode
+
+	insensitive        bool
+	insensitiveChecked bool
+	writeErr           error
+	writeChecked       bool
+}
+
+// writableFS 能够判断目录是否可写的文件系统，覆盖层用它预测权限错误
+type writableFS interface {
+	writable(dir string) error
+}
+
+// newOverlayFS 创建 base 之上的覆盖层
+func newOverlayFS(base FS) *overlayFS {
+	return &overlayFS{base: base, roots: make(map[string]*overlayNode)}
+}
+
+// root 返回卷的根目录
+func (o *overlayFS) root(volume string) *overlayNode {
+	n, ok := o.roots[volume]
+	if !ok {
+		n = &overlayNode{
+			name:     string(filepath.Separator),
+			basePath: volume + string(filepath.Separator),
+			dir:      true,
+		}
+		o.roots[volume] = n
+	}
+	return n
+}
+
+// load 从 base 读取目录的子条目
+func (o *overlayFS) load(n *overlayNode) error {
+	if n.loaded || !n.dir {
+		return nil
+	}
+	entries, err := o.base.ReadDir(n.basePath)
+	if err != nil {
+		return err
+	}
+	children := make(map[string]*overlayNode, len(entries)+len(n.children))
+	for _, e := range entries {
+		if n.removed[e.Name()] {
+			continue
+		}
+		children[e.Name()] = &overlayNode{
+			name:     e.Name(),
+			basePath: filepath.Join(n.basePath, e.Name()),
+			dir:      e.IsDir(),
+			symlink:  e.Type()&fs.ModeSymlink != 0,
+			parent:   n,
+		}
+	}
+	// compact 保留下来的修改覆盖 base 中的条目
+	for name, c := range n.children {
+		children[name] = c
+	}
+	n.children = children
+	n.loaded = true
+	return nil
+}
+
+// atOrigin reports whether c is the base entry stored under its own name in n
+func (n *overlayNode) atOrigin(c *overlayNode) bool {
+	return n.basePath != "" && c.basePath == filepath.Join(n.basePath, c.name)
+}
+
+// addChild 将条目放入目录
+func (n *overlayNode) addChild(c *overlayNode) {
+	c.parent = n
+	n.children[c.name] = c
+	if n.atOrigin(c) {
+		delete(n.removed, c.name)
+	}
+}
+
+// removeChild 将条目移出目录，记录被移走的 base 条目
+func (n *overlayNode) removeChild(c *overlayNode) {
+	delete(n.children, c.name)
+	if n.atOrigin(c) {
+		if n.removed == nil {
+			n.removed = make(map[string]bool)
+		}
+		n.removed[c.name] = true
+	}
+}
+
+// compact 丢弃从 base 读取且未被修改的条目，只保留重命名和新建目录的结果，
+// 之后访问时重新从 base 读取。流式预览在每批执行后调用，使内存占用不随已处理的条目增长
+func (o *overlayFS) compact() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, n := range o.roots {
+		n.compact()
+	}
+}
+
+// compact 压缩子树，返回子树中是否有需要保留的修改
+func (n *overlayNode) compact() bool {
+	for name, c := range n.children {
+		if !c.compact() && n.atOrigin(c) {
+			delete(n.children, name)
+		}
+	}
+	if n.basePath != "" {
+		n.loaded = false
+		n.insensitiveChecked = false
+		n.writeChecked = false
+	}
+	return len(n.children) > 0 || len(n.removed) > 0
+}
+
+// child 查找目录中的条目，不区分大小写的目录按大小写折叠比较
+func (o *overlayFS) child(dir *overlayNode, name string) (*overlayNode, error) {
+	if err := o.load(dir); err != nil {
+		return nil, err
+	}
+	if n, ok := dir.children[name]; ok {
+		return n, nil
+	}
+	if o.caseInsensitive(dir) {
+		for key, n := range dir.children {
+			if strings.EqualFold(key, name) {
+				return n, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// lookup 查找路径对应的条目，中间的符号链接总是被跟随，follow 决定是否跟随最后一级
+func (o *overlayFS) lookup(name string, follow bool) (*overlayNode, error) {
+	return o.lookupLinks(name, follow, 0)
+}
+
+func (o *overlayFS) lookupLinks(name string, follow bool, links int) (*overlayNode, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	volume := filepath.VolumeName(abs)
+	n := o.root(volume)
+	parts := splitPath(abs[len(volume):])
+	for i, part := range parts {
+		if !n.dir {
+			return nil, syscall.ENOTDIR
+		}
+		child, err := o.child(n, part)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			return nil, syscall.ENOENT
+		}
+		if child.symlink && (follow || i < len(parts)-1) {
+			if links >= maxSymlinks {
+				return nil, syscall.ELOOP
+			}
+			target, err := o.base.EvalSymlinks(child.basePath)
+			if err != nil {
+				return nil, err
+			}
+			rest := append([]string{target}, parts[i+1:]...)
+			return o.lookupLinks(filepath.Join(rest...), follow, links+1)
+		}
+		n = child
+	}
+	return n, nil
+}
+
+// lookupParent 查找路径的父目录和最后一级名称
+func (o *overlayFS) lookupParent(name string) (*overlayNode, string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, "", err
+	}
+	parent, err := o.lookup(filepath.Dir(abs), true)
+	if err != nil {
+		return nil, "", err
+	}
+	if !parent.dir {
+		return nil, "", syscall.ENOTDIR
+	}
+	if err := o.load(parent); err != nil {
+		return nil, "", err
+	}
+	return parent, filepath.Base(abs), nil
+}
+
+// caseInsensitive 判断目录中的名称是否不区分大小写，新建的目录沿用上级目录的结果
+func (o *overlayFS) caseInsensitive(n *overlayNode) bool {
+	if !n.insensitiveChecked {
+		if n.basePath != "" {
+			n.insensitive = o.base.CaseInsensitive(n.basePath)
+		} else if n.parent != nil {
+			n.insensitive = o.caseInsensitive(n.parent)
+		}
+		n.insensitiveChecked = true
+	}
+	return n.insensitive
+}
+
+// writable 返回在目录中创建或删除条目时会遇到的错误，覆盖层中新建的目录总是可写
+func (o *overlayFS) writable(n *overlayNode) error {
+	if n.basePath == "" {
+		return nil
+	}
+	if !n.writeChecked {
+		if w, ok := o.base.(writableFS); ok {
+			n.writeErr = w.writable(n.basePath)
+		}
+		n.writeChecked = true
+	}
+	return n.writeErr
+}
+
+// stat 返回条目的信息，名称为覆盖层中的当前名称
+func (o *overlayFS) stat(n *overlayNode) (os.FileInfo, error) {
+	info := n.info
+	if info == nil {
+		var err error
+		if info, err = o.base.Lstat(n.basePath); err != nil {
+			return nil, err
+		}
+	}
+	return &overlayInfo{FileInfo: info, name: n.name, node: n}, nil
+}
+
+// path 返回条目在覆盖层中的当前路径
+func (n *overlayNode) path() string {
+	if n.parent == nil {
+		return n.basePath
+	}
+	return filepath.Join(n.parent.path(), n.name)
+}
+
+func (o *overlayFS) Stat(name string) (os.FileInfo, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n, err := o.lookup(name, true)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = o.stat(n); err == nil {
+			return info, nil
+		}
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: underlying(err)}
+}
+
+func (o *overlayFS) Lstat(name string) (os.FileInfo, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n, err := o.lookup(name, false)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = o.stat(n); err == nil {
+			return info, nil
+		}
+	}
+	return nil, &os.PathError{Op: "lstat", Path: name, Err: underlying(err)}
+}
+
+func (o *overlayFS) ReadDir(name string) ([]os.DirEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n, err := o.lookup(name, true)
+	if err == nil && !n.dir {
+		err = syscall.ENOTDIR
+	}
+	if err == nil {
+		err = o.load(n)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "readdirent", Path: name, Err: underlying(err)}
+	}
+
+	names := make([]string, 0, len(n.children))
+	for childName := range n.children {
+		names = append(names, childName)
+	}
+	sort.Strings(names)
+	entries := make([]os.DirEntry, 0, len(names))
+	for _, childName := range names {
+		info, err := o.stat(n.children[childName])
+		if err != nil {
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return entries, nil
+}
+
+func (o *overlayFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return walkFS(o, root, fn)
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n, err := o.lookup(name, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: underlying(err)}
+	}
+	if n.info != nil {
+		return &memFile{info: n.info, reader: bytes.NewReader(nil), dir: n.dir}, nil
+	}
+	return o.base.Open(n.basePath)
+}
+
+func (o *overlayFS) Mkdir(name string, perm os.FileMode) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pathErr := func(err error) error {
+		return &os.PathError{Op: "mkdir", Path: name, Err: underlying(err)}
+	}
+
+	parent, base, err := o.lookupParent(name)
+	if err != nil {
+		return pathErr(err)
+	}
+	if existing, err := o.child(parent, base); err != nil {
+		return pathErr(err)
+	} else if existing != nil {
+		return pathErr(syscall.EEXIST)
+	}
+	if err := o.writable(parent); err != nil {
+		return pathErr(err)
+	}
+	parent.addChild(&overlayNode{
+		name:     base,
+		info:     &memInfo{name: base, mode: fs.ModeDir | perm.Perm(), modTime: time.Now()},
+		dir:      true,
+		loaded:   true,
+		children: make(map[string]*overlayNode),
+	})
+	return nil
+}
+
+// Rename 按 os.Rename 的语义重命名：目标为文件时被覆盖，目标为目录时失败
+func (o *overlayFS) Rename(oldpath, newpath string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: underlying(err)}
+	}
+
+	src, err := o.lookup(oldpath, false)
+	if err != nil {
+		return linkErr(err)
+	}
+	if src.parent == nil {
+		return linkErr(syscall.EBUSY)
+	}
+	parent, base, err := o.lookupParent(newpath)
+	if err != nil {
+		return linkErr(err)
+	}
+	if err := o.writable(src.parent); err != nil {
+		return linkErr(err)
+	}
+	if err := o.writable(parent); err != nil {
+		return linkErr(err)
+	}
+	// 不能把目录移动到自身内部
+	for p := parent; p != nil; p = p.parent {
+		if p == src {
+			return linkErr(syscall.EINVAL)
+		}
+	}
+
+	dst, err := o.child(parent, base)
+	if err != nil {
+		return linkErr(err)
+	}
+	if dst != nil && dst != src {
+		switch {
+		case dst.dir:
+			return linkErr(syscall.EEXIST)
+		case src.dir:
+			return linkErr(syscall.ENOTDIR)
+		}
+		parent.removeChild(dst)
+	}
+
+	src.parent.removeChild(src)
+	src.name = base
+	parent.addChild(src)
+	return nil
+}
+
+func (o *overlayFS) SameFile(a, b os.FileInfo) bool {
+	ai, ok := a.(*overlayInfo)
+	if !ok {
+		return false
+	}
+	bi, ok := b.(*overlayInfo)
+	return ok && ai.node == bi.node
+}
+
+func (o *overlayFS) CaseInsensitive(dir string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n, err := o.lookup(dir, true)
+	if err != nil {
+		return o.base.CaseInsensitive(dir)
+	}
+	return o.caseInsensitive(n)
+}
+
+func (o *overlayFS) EvalSymlinks(path string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n, err := o.lookup(path, true)
+	if err != nil {
+		return "", &os.PathError{Op: "lstat", Path: path, Err: underlying(err)}
+	}
+	return n.path(), nil
+}
+
+// underlying returns the error wrapped by a path or link error, so that
+// errors from the base filesystem are not reported with a nested path
+func underlying(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Err
+	}
+	return err
+}
+
+// overlayInfo 覆盖层中条目的 FileInfo，名称为覆盖层中的当前名称
+type overlayInfo struct {
+	os.FileInfo
+	name string
+	node *overlayNode
+}
+
+func (i *overlayInfo) Name() string { return i.name }
@@ -0,0 +1,154 @@
+package renamer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// listTree returns every path below root in fsys
+func listTree(t *testing.T, fsys FS, root string) []string {
+	t.Helper()
+	var paths []string
+	err := fsys.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir(%s): %v", root, err)
+	}
+	return paths
+}
+
+func TestDryRunMatchesRealRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		mappings [][2]string
+	}{
+		{"chain", []string{"/d/a", "/d/b", "/d/c"}, [][2]string{{"/d/a", "/d/b"}, {"/d/b", "/d/c"}, {"/d/c", "/d/e"}}},
+		{"swap", []string{"/d/a", "/d/b"}, [][2]string{{"/d/a", "/d/b"}, {"/d/b", "/d/a"}}},
+		{"missing source", []string{"/d/a"}, [][2]string{{"/d/x", "/d/y"}}},
+		{"target exists", []string{"/d/a", "/d/b"}, [][2]string{{"/d/a", "/d/b"}}},
+		{"directory with children", []string{"/d/sub/f"}, [][2]string{{"/d/sub", "/d/SUB"}, {"/d/sub/f", "/d/sub/F"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mappings []ReNameResult
+			for _, m := range tt.mappings {
+				mappings = append(mappings, ReNameResult{OldPath: m[0], NewPath: m[1]})
+			}
+
+			dryFS := newMemTree(t, tt.files...)
+			before := listTree(t, dryFS, "/")
+			dry := New(WithFS(dryFS), WithDryRun(true)).ApplyMapping(mappings, ModeNormal)
+			if after := listTree(t, dryFS, "/"); !reflect.DeepEqual(before, after) {
+				t.Errorf("dry run changed the tree: %v -> %v", before, after)
+			}
+
+			real := New(WithFS(newMemTree(t, tt.files...))).ApplyMapping(mappings, ModeNormal)
+			for i := range real {
+				if dry[i].Status != real[i].Status || dry[i].Message != real[i].Message {
+					t.Errorf("%s: dry run %s %q, real run %s %q", real[i].OldPath,
+						dry[i].Status, dry[i].Message, real[i].Status, real[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestDryRunDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(dir, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(WithDryRun(true), WithRules(Rule{Pattern: "^", Replace: "x_"}))
+	r.AddFiles([]string{file})
+	results := r.ApplyBatch()
+	if len(results) != 1 || results[0].Status != StatusSuccess {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("directory modified by the dry run: mtime %v", info.ModTime())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 || entries[0].Name() != "a.txt" {
+		t.Errorf("directory contents changed: %v", entries)
+	}
+}
+
+// overlayNodes counts the nodes held by the overlay
+func overlayNodes(o *overlayFS) int {
+	var count func(n *overlayNode) int
+	count = func(n *overlayNode) int {
+		total := 1
+		for _, c := range n.children {
+			total += count(c)
+		}
+		return total
+	}
+	total := 0
+	for _, n := range o.roots {
+		total += count(n)
+	}
+	return total
+}
+
+func TestOverlayCompact(t *testing.T) {
+	files := []string{"/d/a", "/d/b", "/d/sub/c", "/e/f", "/e/g"}
+	tests := []struct {
+		name     string
+		renames  [][2]string
+		maxNodes int // 最后一次 compact 之后最多保留的节点数
+	}{
+		{"no changes", nil, 1},
+		{"rename in place", [][2]string{{"/d/a", "/d/x"}}, 3},
+		{"rename back", [][2]string{{"/d/a", "/d/x"}, {"/d/x", "/d/a"}}, 1},
+		{"overwrite", [][2]string{{"/d/a", "/d/b"}}, 3},
+		{"move between directories", [][2]string{{"/d/a", "/e/a"}}, 4},
+		{"rename directory", [][2]string{{"/d/sub", "/d/SUB"}, {"/d/SUB/c", "/d/SUB/C"}}, 4},
+		{"rename into new directory", [][2]string{{"/d/a", "/d/new/a"}}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			real := newMemTree(t, files...)
+			o := newOverlayFS(newMemTree(t, files...))
+			for _, rename := range tt.renames {
+				for _, fsys := range []FS{real, o} {
+					if dir := filepath.Dir(rename[1]); !exists(fsys, dir) {
+						if err := fsys.Mkdir(dir, 0755); err != nil {
+							t.Fatalf("Mkdir(%s): %v", dir, err)
+						}
+					}
+					if err := fsys.Rename(rename[0], rename[1]); err != nil {
+						t.Fatalf("Rename(%s, %s): %v", rename[0], rename[1], err)
+					}
+				}
+				o.compact()
+			}
+			o.compact()
+			if nodes := overlayNodes(o); nodes > tt.maxNodes {
+				t.Errorf("overlay holds %d nodes, want at most %d", nodes, tt.maxNodes)
+			}
+			if got, want := listTree(t, o, "/"), listTree(t, real, "/"); !reflect.DeepEqual(got, want) {
+				t.Errorf("overlay tree = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package renamer
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"sync"
+)
+
+// parallel calls fn for every index in [0, n) on at most workers goroutines.
+// No further indexes are handed out once ctx is cancelled; ctx.Err() is
+// returned after the running calls have finished.
+func parallel(ctx context.Context, n, workers int, fn func(i int)) error {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			fn(i)
+		}
+		return ctx.Err()
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// SetWorkers 设置生成映射时并发执行规则的 goroutine 数，0 表示使用 CPU 数
+func (r *ReNamer) SetWorkers(workers int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Workers = workers
+}
+
+// WithWorkers 设置生成映射时并发执行规则的 goroutine 数，0 表示使用 CPU 数
+func WithWorkers(workers int) Option {
+	return func(r *ReNamer) {
+		r.Workers = workers
+	}
+}
+
+// workers returns the number of goroutines used to generate mappings
+func (r *ReNamer) workers() int {
+	if r.Workers > 0 {
+		return r.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// SetParallel 设置同时执行的重命名数，适用于每次重命名延迟较高的网络文件系统，0 或 1 表示逐个执行
+func (r *ReNamer) SetParallel(parallel int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Parallel = parallel
+}
+
+// WithParallel 设置同时执行的重命名数，适用于每次重命名延迟较高的网络文件系统，0 或 1 表示逐个执行
+func WithParallel(parallel int) Option {
+	return func(r *ReNamer) {
+		r.Parallel = parallel
+	}
+}
+
+// parallel returns the number of renames executed at the same time
+func (r *ReNamer) parallel() int {
+	if r.Parallel > 1 {
+		return r.Parallel
+	}
+	return 1
+}
+
+// regexpCache 批次内共享的正则表达式缓存，每个模式只编译一次。
+// 命中时只加读锁，编译在锁外进行
+type regexpCache struct {
+	mu       sync.RWMutex
+	compiled map[string]*regexp.Regexp
+	errs     map[string]error
+}
+
+func newRegexpCache() *regexpCache {
+	return &regexpCache{
+		compiled: make(map[string]*regexp.Regexp),
+		errs:     make(map[string]error),
+	}
+}
+
+// compile returns the compiled form of pattern, compiling it on first use.
+// Workers that miss at the same time may compile the pattern concurrently,
+// the first result stored is the one every caller gets.
+func (c *regexpCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.RLock()
+	re, ok := c.compiled[pattern]
+	err, failed := c.errs[pattern]
+	c.mu.RUnlock()
+	if ok {
+		return re, nil
+	}
+	if failed {
+		return nil, err
+	}
+
+	re, err = regexp.Compile(pattern)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.errs[pattern] = err
+		return nil, err
+	}
+	if existing, ok := c.compiled[pattern]; ok {
+		return existing, nil
+	}
+	c.compiled[pattern] = re
+	return re, nil
+}
+
+// compile 编译规则中的正则表达式，批次内的同一模式只编译一次
+func (c *RuleContext) compile(pattern string) (*regexp.Regexp, error) {
+	if c == nil || c.regexps == nil {
+		return regexp.Compile(pattern)
+	}
+	return c.regexps.compile(pattern)
+}
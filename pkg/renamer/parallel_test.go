@@ -0,0 +1,67 @@
+package renamer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRegexpCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		apply   func(ctx *RuleContext) error
+		pattern string
+	}{
+		{"rule pattern", func(ctx *RuleContext) error {
+			_, err := Rule{Pattern: `\d+`, Replace: "n"}.ApplyContext("a1", ctx)
+			return err
+		}, `\d+`},
+		{"regex filter", func(ctx *RuleContext) error {
+			_, err := Rule{Pattern: "^.*$", Replace: `{name|regex:[a-z]+}`}.ApplyContext("a1", ctx)
+			return err
+		}, `[a-z]+`},
+		{"match builtin", func(ctx *RuleContext) error {
+			_, err := Rule{Type: RuleTypeExpr, Replace: `match(name, "^a") ? "x" : "y"`}.ApplyContext("a1", ctx)
+			return err
+		}, `^a`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newRegexpCache()
+			var wg sync.WaitGroup
+			errs := make([]error, 8)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = tt.apply(&RuleContext{Path: "/d/a1", fs: NewMemFS(), regexps: cache})
+				}(i)
+			}
+			wg.Wait()
+			for _, err := range errs {
+				if err != nil {
+					t.Fatalf("apply: %v", err)
+				}
+			}
+			if _, ok := cache.compiled[tt.pattern]; !ok {
+				t.Errorf("pattern %q was not compiled through the batch cache", tt.pattern)
+			}
+		})
+	}
+}
+
+func TestRegexpCacheCompile(t *testing.T) {
+	cache := newRegexpCache()
+	first, err := cache.compile(`a+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := cache.compile(`a+`); again != first {
+		t.Error("a cached pattern was compiled again")
+	}
+	if _, err := cache.compile(`(`); err == nil {
+		t.Error("invalid pattern compiled")
+	}
+	if _, ok := cache.errs[`(`]; !ok {
+		t.Error("compile error was not cached")
+	}
+}
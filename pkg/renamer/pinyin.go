@@ -0,0 +1,187 @@
+package renamer
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed data/pinyin.txt
+var pinyinData string
+
+// pinyinPhraseData 多音字的词语读音，优先于单字读音
+//
+//go:embed data/pinyin_phrases.txt
+var pinyinPhraseData string
+
+var (
+	pinyinOnce  sync.Once
+	pinyinTable map[rune]string
+	// pinyinPhrases 词语到逐字读音的映射，pinyinPhraseLen 为最长词语的字数
+	pinyinPhrases   map[string][]string
+	pinyinPhraseLen int
+)
+
+// 拼音声调的显示方式
+const (
+	ToneNone   = "none"   // 不带声调：bao
+	ToneMark   = "mark"   // 声调符号：bào
+	ToneNumber = "number" // 数字声调：bao4
+)
+
+// toneMarks 声调对应的组合附加符号
+var toneMarks = []string{"", "̄", "́", "̌", "̀"}
+
+// loadPinyinTable 解析内置的拼音表
+func loadPinyinTable() {
+	pinyinTable = make(map[rune]string)
+	var cp rune
+	for _, line := range strings.Split(pinyinData, "\n") {
+		switch {
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "@"):
+			v, err := strconv.ParseInt(line[1:], 16, 32)
+			if err != nil {
+				panic(fmt.Sprintf("invalid pinyin table block %q", line))
+			}
+			cp = rune(v)
+			continue
+		case line != "":
+			pinyinTable[cp] = line
+		}
+		cp++
+	}
+
+	pinyinPhrases = make(map[string][]string)
+	for _, line := range strings.Split(pinyinPhraseData, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		n := utf8.RuneCountInString(fields[0])
+		if n != len(fields)-1 {
+			panic(fmt.Sprintf("invalid pinyin phrase %q", line))
+		}
+		pinyinPhrases[fields[0]] = fields[1:]
+		if n > pinyinPhraseLen {
+			pinyinPhraseLen = n
+		}
+	}
+}
+
+// lookupPinyin 返回汉字的数字声调拼音，没有读音时返回 false
+func lookupPinyin(c rune) (string, bool) {
+	pinyinOnce.Do(loadPinyinTable)
+	py, ok := pinyinTable[c]
+	return py, ok
+}
+
+// lookupPinyinPhrase 返回 runes 开头最长的多音字词语的逐字读音，没有时返回 nil
+func lookupPinyinPhrase(runes []rune) []string {
+	pinyinOnce.Do(loadPinyinTable)
+	for n := pinyinPhraseLen; n > 0; n-- {
+		if n > len(runes) {
+			continue
+		}
+		if py, ok := pinyinPhrases[string(runes[:n])]; ok {
+			return py
+		}
+	}
+	return nil
+}
+
+// formatPinyin 按声调方式格式化数字声调拼音，v 用于在不带声调符号时替代 ü
+func formatPinyin(numeric, tone, v string) string {
+	syllable, t := numeric, 0
+	if last := numeric[len(numeric)-1]; last >= '1' && last <= '4' {
+		syllable, t = numeric[:len(numeric)-1], int(last-'0')
+	}
+
+	switch tone {
+	case ToneMark:
+		return markTone(syllable, t)
+	case ToneNumber:
+		syllable = strings.ReplaceAll(syllable, "ü", v)
+		if t > 0 {
+			syllable += strconv.Itoa(t)
+		}
+		return syllable
+	default:
+		return strings.ReplaceAll(syllable, "ü", v)
+	}
+}
+
+// markTone 按标调规则在音节上加声调符号：
+// 有 a 或 e 标在其上，ou 标在 o 上，否则标在最后一个元音上
+func markTone(syllable string, tone int) string {
+	if tone == 0 {
+		return syllable
+	}
+
+	pos := strings.IndexAny(syllable, "ae")
+	if pos < 0 && strings.Contains(syllable, "ou") {
+		pos = strings.IndexRune(syllable, 'o')
+	}
+	if pos < 0 {
+		pos = strings.LastIndexAny(syllable, "iouü")
+	}
+	if pos < 0 {
+		return syllable
+	}
+
+	_, size := utf8.DecodeRuneInString(syllable[pos:])
+	return norm.NFC.String(syllable[:pos+size] + toneMarks[tone] + syllable[pos+size:])
+}
+
+// applyPinyin 汉字转拼音规则的处理函数。多音字先按内置词表（data/pinyin_phrases.txt）识别词语，
+// 词表之外的字使用最常见的单字读音，因此不在词表中的多音字可能读错
+func applyPinyin(r Rule, filename string, ctx *RuleContext) (string, error) {
+	tone := r.option("tone", ToneNone)
+	if tone != ToneNone && tone != ToneMark && tone != ToneNumber {
+		return filename, fmt.Errorf("未知的声调方式 '%s'", tone)
+	}
+	separator := r.option("separator", "")
+	initials := r.option("initials", "false") == "true"
+	v := r.option("v", "v")
+
+	var sb strings.Builder
+	prevHan := false
+	write := func(py string) {
+		if prevHan {
+			sb.WriteString(separator)
+		}
+		if initials {
+			sb.WriteString(py[:1])
+		} else {
+			sb.WriteString(formatPinyin(py, tone, v))
+		}
+		prevHan = true
+	}
+
+	runes := []rune(filename)
+	for i := 0; i < len(runes); {
+		if phrase := lookupPinyinPhrase(runes[i:]); phrase != nil {
+			for _, py := range phrase {
+				write(py)
+			}
+			i += len(phrase)
+			continue
+		}
+
+		py, ok := lookupPinyin(runes[i])
+		if ok {
+			write(py)
+		} else {
+			sb.WriteRune(runes[i])
+			prevHan = false
+		}
+		i++
+	}
+	return sb.String(), nil
+}
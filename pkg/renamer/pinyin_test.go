@@ -0,0 +1,36 @@
+package renamer
+
+import "testing"
+
+func TestPinyin(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"no tone", rf.Pinyin(ToneNone, "", false), "北京", "beijing"},
+		{"separator", rf.Pinyin(ToneNone, "_", false), "北京", "bei_jing"},
+		{"tone mark", rf.Pinyin(ToneMark, " ", false), "北京", "běi jīng"},
+		{"tone number", rf.Pinyin(ToneNumber, " ", false), "北京", "bei3 jing1"},
+		{"initials", rf.Pinyin(ToneNone, "", true), "北京", "bj"},
+		{"polyphone phrases", rf.Pinyin(ToneNone, "", false), "重庆银行", "chongqingyinhang"},
+		{"polyphone tones", rf.Pinyin(ToneNumber, " ", false), "音乐会计", "yin1 yue4 kuai4 ji4"},
+		{"longest phrase", rf.Pinyin(ToneNone, " ", false), "董事长", "dong shi zhang"},
+		{"phrase initials", rf.Pinyin(ToneNone, "", true), "重庆", "cq"},
+		{"character override", rf.Pinyin(ToneNone, "", false), "长城地图", "changchengditu"},
+		{"unlisted polyphone", rf.Pinyin(ToneNone, "", false), "两行字", "liangxingzi"},
+		{"mixed", rf.Pinyin(ToneNone, "", false), "第1集", "di1ji"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package renamer
+
+// Progress 批量执行的进度
+type Progress struct {
+	Planned int    // 本次要执行的条目数
+	Renamed int    // 已成功的条目数
+	Failed  int    // 已失败的条目数
+	Skipped int    // 因冲突跳过的条目数
+	Current string // 正在处理的路径，批次结束或被取消时为空
+}
+
+// Done 返回已处理的条目数
+func (p Progress) Done() int {
+	return p.Renamed + p.Failed + p.Skipped
+}
+
+// ProgressFunc 接收进度的回调，在执行重命名的 goroutine 中同步调用，应尽快返回
+type ProgressFunc func(p Progress)
+
+// ProgressChan 返回把进度发送到 ch 的回调。发送不会阻塞，
+// ch 已满时丢弃这次进度，最终结果以 ApplyBatchContext 等方法的返回值为准
+func ProgressChan(ch chan<- Progress) ProgressFunc {
+	return func(p Progress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// WithProgress 设置执行时的进度回调
+func WithProgress(fn ProgressFunc) Option {
+	return func(r *ReNamer) {
+		r.progress = fn
+	}
+}
+
+// SetProgress 设置执行时的进度回调，为 nil 时不报告进度
+func (r *ReNamer) SetProgress(fn ProgressFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = fn
+}
+
+// report sends p to the progress callback, if any
+func (r *ReNamer) report(p Progress) {
+	if r.progress != nil {
+		r.progress(p)
+	}
+}
+
+// count records the outcome of one entry
+func (p *Progress) count(status ReNameStatus) {
+	switch status {
+	case StatusSuccess:
+		p.Renamed++
+	case StatusSkipped:
+		p.Skipped++
+	default:
+		p.Failed++
+	}
+}
+
+// add returns the sum of the counts of p and q with the current path of q
+func (p Progress) add(q Progress) Progress {
+	return Progress{
+		Planned: p.Planned + q.Planned,
+		Renamed: p.Renamed + q.Renamed,
+		Failed:  p.Failed + q.Failed,
+		Skipped: p.Skipped + q.Skipped,
+		Current: q.Current,
+	}
+}
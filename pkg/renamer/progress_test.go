@@ -0,0 +1,68 @@
+package renamer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestProgressAndCancel(t *testing.T) {
+	var files []string
+	for i := 0; i < 10; i++ {
+		files = append(files, fmt.Sprintf("/d/f%d", i))
+	}
+	tests := []struct {
+		name        string
+		cancelAfter int // 第几次进度回调时取消，0 表示不取消
+		wantRenamed int
+		wantErr     error
+	}{
+		{"complete", 0, 10, nil},
+		{"cancelled", 3, 3, context.Canceled}, // 已报告为 Current 的条目会执行完
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, files...)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var reports []Progress
+			r := New(WithFS(fsys), WithRules(Rule{Pattern: "^", Replace: "x_"}), WithProgress(func(p Progress) {
+				reports = append(reports, p)
+				if len(reports) == tt.cancelAfter {
+					cancel()
+				}
+			}))
+			r.AddFiles(files)
+			results, err := r.ApplyBatchContext(ctx)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			renamed, pending := 0, 0
+			for _, result := range results {
+				switch result.Status {
+				case StatusSuccess:
+					renamed++
+				case StatusPending:
+					pending++
+				default:
+					t.Errorf("%s: status %s (%s)", result.OldPath, result.Status, result.Message)
+				}
+			}
+			if renamed != tt.wantRenamed || renamed+pending != len(files) {
+				t.Errorf("renamed %d, pending %d, want %d renamed", renamed, pending, tt.wantRenamed)
+			}
+
+			last := reports[len(reports)-1]
+			if last.Current != "" || last.Planned != len(files) || last.Renamed != renamed {
+				t.Errorf("final progress = %+v", last)
+			}
+			for i := 1; i < len(reports); i++ {
+				if reports[i].Done() < reports[i-1].Done() {
+					t.Errorf("progress went backwards: %+v after %+v", reports[i], reports[i-1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package renamer
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// RuleKind 自定义规则类型。注册后 Rule.Type 等于 Type() 的规则由 Apply 处理，
+// 规则参数通过 Rule.Pattern、Rule.Replace 和 Rule.Options 传入，因此可以随 SaveRule/LoadRule 一起保存
+type RuleKind interface {
+	// Type 返回规则类型名称，即 JSON 中的 type 字段
+	Type() string
+	// Apply 对文件名应用规则，返回新文件名。生成映射时不同文件的 Apply 会被并发调用
+	Apply(rule Rule, filename string, ctx *RuleContext) (string, error)
+}
+
+// PlaceholderFunc 自定义模板变量的取值函数，可能被并发调用
+type PlaceholderFunc func(ctx *RuleContext) string
+
+// registryMu 保护规则类型和模板变量的注册表
+var registryMu sync.RWMutex
+
+// customRuleKinds 通过 RegisterRuleKind 注册的规则类型
+var customRuleKinds = map[string]RuleKind{}
+
+// identPattern 规则类型和模板变量的合法名称
+var identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RegisterRuleKind 注册自定义规则类型，名称不能与内置或已注册的类型重复，否则返回 ErrRegistration 分类的错误
+func RegisterRuleKind(kind RuleKind) error {
+	name := kind.Type()
+	if !identPattern.MatchString(name) {
+		return newError(ErrRegistration, "", "无效的规则类型名称 '%s'", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := ruleTransforms[name]; ok || name == RuleTypeRegex {
+		return newError(ErrRegistration, "", "规则类型 '%s' 已注册", name)
+	}
+	customRuleKinds[name] = kind
+	ruleTransforms[name] = kind.Apply
+	return nil
+}
+
+// RegisterPlaceholder 注册自定义模板变量，可在模板中以 {name} 或 {name|filter} 引用，
+// 也可以在表达式规则中直接使用。名称不能与内置或已注册的变量重复，否则返回 ErrRegistration 分类的错误
+func RegisterPlaceholder(name string, fn PlaceholderFunc) error {
+	if !identPattern.MatchString(name) {
+		return newError(ErrRegistration, "", "无效的模板变量名称 '%s'", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := builtinVars[name]; ok {
+		return newError(ErrRegistration, "", "模板变量 '%s' 已注册", name)
+	}
+	builtinVars[name] = func(ctx *RuleContext) (string, bool) {
+		return fn(ctx), true
+	}
+	return nil
+}
+
+// RuleKinds 返回已注册的自定义规则类型名称，按名称排序
+func RuleKinds() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(customRuleKinds))
+	for name := range customRuleKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupTransform 查找规则类型的处理函数
+func lookupTransform(ruleType string) (ruleTransform, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	transform, ok := ruleTransforms[ruleType]
+	return transform, ok
+}
+
+// lookupPlaceholder 查找内置或注册的模板变量
+func lookupPlaceholder(name string) (func(ctx *RuleContext) (string, bool), bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, ok := builtinVars[name]
+	return fn, ok
+}
+
+// validateRuleType 检查规则类型是否已知
+func validateRuleType(ruleType string) error {
+	if ruleType == "" || ruleType == RuleTypeRegex {
+		return nil
+	}
+	if _, ok := lookupTransform(ruleType); !ok {
+		return newError(ErrUnknownRuleType, "", "未知的规则类型 '%s'", ruleType)
+	}
+	return nil
+}
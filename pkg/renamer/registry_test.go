@@ -0,0 +1,92 @@
+package renamer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// reverseKind reverses the file name
+type reverseKind struct{ name string }
+
+func (k reverseKind) Type() string { return k.name }
+
+func (k reverseKind) Apply(_ Rule, filename string, _ *RuleContext) (string, error) {
+	runes := []rune(filename)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes), nil
+}
+
+// 注册表是全局的，测试用的类型和变量在进程中只注册一次，使 -count 大于 1 时也能通过
+var (
+	registerOnce   sync.Once
+	registerKind   error
+	registerHolder error
+)
+
+func registerTestKinds() {
+	registerOnce.Do(func() {
+		registerKind = RegisterRuleKind(reverseKind{"testReverse"})
+		registerHolder = RegisterPlaceholder("testUpperPath", func(ctx *RuleContext) string {
+			return strings.ToUpper(ctx.Path)
+		})
+	})
+}
+
+func TestRegisterRuleKind(t *testing.T) {
+	registerTestKinds()
+	if registerKind != nil {
+		t.Fatalf("RegisterRuleKind: %v", registerKind)
+	}
+	// 已注册、内置和无效的名称都不能注册
+	for _, kind := range []string{"testReverse", RuleTypeRegex, RuleTypePinyin, "bad name", ""} {
+		if err := RegisterRuleKind(reverseKind{kind}); !errors.Is(err, ErrRegistration) {
+			t.Errorf("RegisterRuleKind(%q) = %v, want ErrRegistration", kind, err)
+		}
+	}
+
+	found := false
+	for _, kind := range RuleKinds() {
+		found = found || kind == "testReverse"
+	}
+	if !found {
+		t.Errorf("RuleKinds() = %v, missing testReverse", RuleKinds())
+	}
+	if got, err := (Rule{Type: "testReverse"}).Apply("abc"); err != nil || got != "cba" {
+		t.Errorf("Apply = %q, %v, want %q", got, err, "cba")
+	}
+}
+
+func TestRegisterPlaceholder(t *testing.T) {
+	registerTestKinds()
+	if registerHolder != nil {
+		t.Fatalf("RegisterPlaceholder: %v", registerHolder)
+	}
+	for _, name := range []string{"testUpperPath", "index", "bad-name"} {
+		if err := RegisterPlaceholder(name, func(*RuleContext) string { return "" }); !errors.Is(err, ErrRegistration) {
+			t.Errorf("RegisterPlaceholder(%q) = %v, want ErrRegistration", name, err)
+		}
+	}
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"template", Rule{Pattern: `^.*$`, Replace: "{testUpperPath|replace:/:_}"}, "_D_A"},
+		{"expression", Rule{Type: RuleTypeExpr, Replace: `testUpperPath + "!"`}, "/D/A!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.ApplyContext("a", &RuleContext{Path: "/d/a"})
+			if err != nil {
+				t.Fatalf("ApplyContext: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,718 @@
+package renamer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ReNameResult represents the result of a rename operation with error handling
+type ReNameResult struct {
+	OldPath string       `json:"oldPath"`
+	NewPath string       `json:"newPath"`
+	Status  ReNameStatus `json:"status"`            // Status: success, error, pending
+	Message string       `json:"message,omitempty"` // Detailed message, only shown when needed
+	Err     error        `json:"-"`                 // Typed error for failed entries, see the Err* values
+}
+
+// fail marks the result as failed with err
+func (m *ReNameResult) fail(err error) {
+	m.Status = StatusError
+	m.Message = err.Error()
+	m.Err = err
+}
+
+// ReNamer represents the file renaming manager.
+// FileList may contain both files and directories; directory names are
+// treated as having no extension.
+//
+// The methods of ReNamer are safe for concurrent use: setters and list
+// methods are synchronised, and every batch works on a snapshot of the rules,
+// file list and settings taken when it starts, so the configuration may be
+// edited while a batch runs in the background. Reading or writing the
+// exported fields directly is not synchronised.
+type ReNamer struct {
+	Rules            []Rule         `json:"operations"`
+	FileList         []string       `json:"files"`
+	DryRun           bool           `json:"dryRun"`                   // Whether in preview mode, simulated without touching the files
+	Mappings         []ReNameResult `json:"mappings"`                 // Results of nename mappings
+	ProcessExtension bool           `json:"processExtension"`         // Whether to process file extension
+	Root             string         `json:"root,omitempty"`           // Sandbox root, entries outside it are never touched
+	AllowMove        bool           `json:"allowMove"`                // Whether targets may be in another directory
+	AllowExec        bool           `json:"allowExec"`                // Whether exec rules may run external commands
+	Platform         Platform       `json:"platform,omitempty"`       // Target platform used to validate generated names
+	ConflictPolicy   ConflictPolicy `json:"conflictPolicy,omitempty"` // How colliding targets are handled
+	Workers          int            `json:"workers,omitempty"`        // Goroutines generating mappings, 0 means one per CPU
+	ChunkSize        int            `json:"chunkSize,omitempty"`      // Entries per chunk when streaming, 0 means 1000
+	Parallel         int            `json:"parallel,omitempty"`       // Renames executed at the same time, 0 or 1 means one at a time
+
+	mu       sync.RWMutex // Guards the fields while r is shared
+	fs       FS           // Filesystem the batch is executed against
+	progress ProgressFunc // Called while the batch is executed
+}
+
+func NewReNamer() *ReNamer {
+	return &ReNamer{
+		Rules:            make([]Rule, 0),
+		FileList:         make([]string, 0),
+		DryRun:           false,
+		Mappings:         make([]ReNameResult, 0),
+		ProcessExtension: false, // 默认不处理扩展名
+		Root:             "",
+		AllowMove:        false, // 默认只允许在原目录内重命名
+		AllowExec:        false, // 默认不允许 exec 规则执行外部命令
+		Platform:         PlatformAuto,
+		ConflictPolicy:   ConflictError, // 默认冲突时报错
+		fs:               OSFS{},
+	}
+}
+
+// SetPlatform 设置校验文件名时使用的目标平台
+func (r *ReNamer) SetPlatform(platform Platform) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Platform = platform
+}
+
+// SetProcessExtension 设置是否处理文件扩展名
+func (r *ReNamer) SetProcessExtension(process bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ProcessExtension = process
+}
+
+// pendingMapping holds the intermediate state of a single file while the
+// rules are applied
+type pendingMapping struct {
+	result ReNameResult
+	dir    string
+	ext    string
+	name   string // 当前文件名，规则依次作用于上一条规则的结果
+	ctx    *RuleContext
+}
+
+// failed marks the mapping as failed
+func (p *pendingMapping) failed(err error) {
+	p.result.fail(err)
+}
+
+// prepareMapping validates path and splits it into the parts the rules work on,
+// index is the position of the file in the batch
+func (r *ReNamer) prepareMapping(path string, index int) *pendingMapping {
+	p := &pendingMapping{
+		result: ReNameResult{
+			OldPath: path,
+			Status:  StatusPending,
+		},
+		ctx: &RuleContext{Index: index, Path: path, fs: r.filesystem(), allowExec: r.AllowExec},
+	}
+
+	if path == "" {
+		p.failed(newError(ErrInvalidPath, path, "Empty file path"))
+		return p
+	}
+
+	// Split path and filename
+	dir, srcName := filepath.Split(path)
+	if srcName == "" {
+		p.failed(newError(ErrInvalidPath, path, "Invalid file path"))
+		return p
+	}
+
+	ext := filepath.Ext(srcName)
+	if r.isDir(path) {
+		// 目录名不区分扩展名
+		ext = ""
+	}
+	p.dir, p.ext, p.name = dir, ext, srcName
+	if !r.ProcessExtension {
+		p.name = srcName[:len(srcName)-len(ext)]
+	}
+	return p
+}
+
+// activeMappings returns the mappings that have not failed yet
+func activeMappings(pending []*pendingMapping) []*pendingMapping {
+	active := make([]*pendingMapping, 0, len(pending))
+	for _, p := range pending {
+		if p.result.Status != StatusError {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// isBatchRule reports whether rule receives all names in a single call
+func isBatchRule(rule Rule) bool {
+	_, ok := batchTransforms[rule.Type]
+	return ok
+}
+
+// applyBatchRule applies a batch rule to every mapping that has not failed yet
+func applyBatchRule(ctx context.Context, rule Rule, pending []*pendingMapping) error {
+	active := activeMappings(pending)
+	if len(active) == 0 {
+		return nil
+	}
+
+	items := make([]batchItem, len(active))
+	for i, p := range active {
+		items[i] = batchItem{Name: p.name, Ctx: p.ctx}
+	}
+	batchTransforms[rule.Type](rule, items)
+	for i, p := range active {
+		p.setName(items[i].Name, items[i].Err)
+		if items[i].Message != "" {
+			p.result.Message = items[i].Message
+		}
+	}
+	return ctx.Err()
+}
+
+// applyRules applies a run of per-file rules to every mapping that has not
+// failed yet. Files are processed concurrently, each by a single worker that
+// applies the whole run in order, so the results do not depend on scheduling.
+func (r *ReNamer) applyRules(ctx context.Context, rules []Rule, pending []*pendingMapping) error {
+	active := activeMappings(pending)
+	return parallel(ctx, len(active), r.workers(), func(i int) {
+		p := active[i]
+		for _, rule := range rules {
+			name, err := rule.ApplyContext(p.name, p.ctx)
+			p.setName(name, err)
+			if p.result.Status == StatusError {
+				return
+			}
+		}
+	})
+}
+
+// setName records the result of a rule
+func (p *pendingMapping) setName(name string, err error) {
+	if err != nil {
+		p.failed(wrapError(ErrRule, p.result.OldPath, err))
+		return
+	}
+	if name == "" {
+		p.failed(newError(ErrInvalidName, p.result.OldPath, "Generated filename is invalid"))
+		return
+	}
+	p.name = name
+}
+
+// finishMapping builds and validates the target path once all rules are applied
+func (r *ReNamer) finishMapping(p *pendingMapping) ReNameResult {
+	if p.result.Status == StatusError {
+		return p.result
+	}
+
+	dst := p.name
+	if !r.ProcessExtension {
+		dst = dst + p.ext
+	}
+	if err := r.validateName(dst); err != nil {
+		p.failed(err)
+		return p.result
+	}
+	p.result.NewPath = filepath.Join(p.dir, dst)
+
+	if err := r.validateMapping(p.result.OldPath, p.result.NewPath); err != nil {
+		p.failed(err)
+	}
+	return p.result
+}
+
+// generateMappings generates rename mappings for files. Rules are applied
+// one at a time across the whole batch so that batch rules can process all
+// names in a single call; per-file rules run on a bounded worker pool and
+// regular expressions are compiled once per batch. The output keeps the
+// order of files. Colliding targets are resolved according to the conflict
+// policy. first is the batch index of the first file.
+func (r *ReNamer) generateMappings(ctx context.Context, files []string, first int) ([]ReNameResult, error) {
+	regexps := newRegexpCache()
+	pending := make([]*pendingMapping, len(files))
+	err := parallel(ctx, len(files), r.workers(), func(i int) {
+		pending[i] = r.prepareMapping(files[i], first+i)
+		pending[i].ctx.regexps = regexps
+		pending[i].ctx.batch = ctx
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 连续的逐文件规则作为一组并发执行，批量规则在组之间整批执行
+	for start := 0; start < len(r.Rules); {
+		if isBatchRule(r.Rules[start]) {
+			if err := applyBatchRule(ctx, r.Rules[start], pending); err != nil {
+				return nil, err
+			}
+			start++
+			continue
+		}
+		end := start + 1
+		for end < len(r.Rules) && !isBatchRule(r.Rules[end]) {
+			end++
+		}
+		if err := r.applyRules(ctx, r.Rules[start:end], pending); err != nil {
+			return nil, err
+		}
+		start = end
+	}
+
+	mappings := make([]ReNameResult, len(files))
+	err = parallel(ctx, len(pending), r.workers(), func(i int) {
+		mappings[i] = r.finishMapping(pending[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.resolveConflicts(mappings)
+	return mappings, nil
+}
+
+// pathDepth returns the number of path components in path
+func pathDepth(path string) int {
+	return strings.Count(filepath.Clean(path), string(filepath.Separator))
+}
+
+// executionOrder returns the order in which mappings should be executed.
+// Renames are performed deepest-first so that renaming a directory does not
+// invalidate the paths of entries inside it; undo runs shallowest-first
+// because the parent directory must be restored before its children.
+func executionOrder(mappings []ReNameResult, mode ReNameMode) []int {
+	order := make([]int, len(mappings))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		if mode == ModeUndo {
+			return pathDepth(mappings[order[a]].NewPath) < pathDepth(mappings[order[b]].NewPath)
+		}
+		return pathDepth(mappings[order[a]].OldPath) > pathDepth(mappings[order[b]].OldPath)
+	})
+	return order
+}
+
+func (r *ReNamer) SetDryRun(dryRun bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.DryRun = dryRun
+}
+
+func (r *ReNamer) AddFiles(files []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FileList = append(r.FileList, files...)
+}
+
+func (r *ReNamer) RemoveFile(file string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, f := range r.FileList {
+		if f == file {
+			r.FileList = append(r.FileList[:i], r.FileList[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ReNamer) ClearFiles() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FileList = make([]string, 0)
+}
+
+func (r *ReNamer) GetFiles() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	files := make([]string, len(r.FileList))
+	copy(files, r.FileList)
+	return files
+}
+
+func (r *ReNamer) AddRule(rule Rule) string {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Rules = append(r.Rules, rule)
+	return rule.ID
+}
+
+func (r *ReNamer) RemoveRuleByID(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, op := range r.Rules {
+		if op.ID == id {
+			r.Rules = append(r.Rules[:i], r.Rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ReNamer) RemoveRuleByName(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	newOperations := make([]Rule, 0)
+	removedCount := 0
+
+	for _, op := range r.Rules {
+		if op.Name != name {
+			newOperations = append(newOperations, op)
+		} else {
+			removedCount++
+		}
+	}
+
+	r.Rules = newOperations
+	return removedCount
+}
+
+func (r *ReNamer) GetRuleByID(id string) *Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, op := range r.Rules {
+		if op.ID == id {
+			op = op.clone()
+			return &op
+		}
+	}
+	return nil
+}
+
+func (r *ReNamer) SaveRule() ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return json.Marshal(r.Rules)
+}
+
+// LoadRule loads rules saved by SaveRule. Every rule type, including custom
+// kinds, must be registered before loading.
+func (r *ReNamer) LoadRule(data []byte) error {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := validateRuleType(rule.Type); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Rules = rules
+	return nil
+}
+
+// ApplyBatch processes all files in the internal file list in batch
+func (r *ReNamer) ApplyBatch() []ReNameResult {
+	results, _ := r.ApplyBatchContext(context.Background())
+	return results
+}
+
+// ApplyBatchContext generates the mappings for the internal file list and
+// executes them. If ctx is cancelled while the mappings are generated nothing
+// is renamed; if it is cancelled during execution the remaining entries keep
+// their pending status. In both cases ctx.Err() is returned.
+// The batch uses a snapshot of the file list, rules and settings taken when
+// it starts.
+func (r *ReNamer) ApplyBatchContext(ctx context.Context) ([]ReNameResult, error) {
+	batch := r.Snapshot()
+
+	// 生成映射
+	mappings, err := batch.generateMappings(ctx, batch.FileList, 0)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.Mappings = append([]ReNameResult(nil), mappings...)
+	r.mu.Unlock()
+
+	return batch.applyMapping(ctx, mappings, ModeNormal)
+}
+
+// ApplyMapping executes the rename mapping list based on the specified mode.
+// Entries are executed deepest path first (shallowest first for undo) so that
+// directory renames do not invalidate other paths in the same batch; the
+// returned results keep the original mapping order.
+// Parameters:
+//   - mappings: List of rename operations to be executed
+//   - mode: Operation mode that determines how mappings are processed:
+//     ModeNormal: Skip mappings with error or skipped status
+//     ModeError: Only retry mappings with error status
+//     ModeUndo: Reverse the rename operation by swapping OldPath and NewPath
+//
+// Returns: List of ReNameResult containing the execution results
+func (r *ReNamer) ApplyMapping(mappings []ReNameResult, mode ReNameMode) []ReNameResult {
+	results, _ := r.ApplyMappingContext(context.Background(), mappings, mode)
+	return results
+}
+
+// ApplyMappingContext is ApplyMapping with cancellation. When ctx is
+// cancelled no further entries are renamed, the results processed so far are
+// returned together with ctx.Err().
+// The progress callback is called before each entry is executed and once more
+// with an empty Current when the batch ends or is cancelled.
+// In DryRun mode the batch is executed against an in-memory overlay of the
+// filesystem, so the results carry the statuses and messages of a real run
+// (collisions, missing sources, permission errors) while nothing is renamed.
+func (r *ReNamer) ApplyMappingContext(ctx context.Context, mappings []ReNameResult, mode ReNameMode) ([]ReNameResult, error) {
+	r.mu.RLock()
+	batch := r.clone()
+	r.mu.RUnlock()
+	return batch.applyMapping(ctx, mappings, mode)
+}
+
+// applyMapping executes mappings with the settings of r, which must not be
+// shared with other goroutines
+func (r *ReNamer) applyMapping(ctx context.Context, mappings []ReNameResult, mode ReNameMode) ([]ReNameResult, error) {
+	// 预览模式在内存覆盖层上执行同样的流程，结果与实际执行一致但不修改文件
+	if r.DryRun {
+		return r.preview().applyMapping(ctx, mappings, mode)
+	}
+
+	results := make([]ReNameResult, len(mappings))
+	copy(results, mappings)
+	switch mode {
+	case ModeNormal, ModeError, ModeUndo:
+	default:
+		for i := range results {
+			results[i].fail(newError(ErrInvalidMode, "", "无效的操作模式"))
+		}
+		return results, nil
+	}
+
+	// 执行实际重命名操作，按依赖关系调度，互不依赖的条目最多同时执行 Parallel 个
+	fsys := r.filesystem()
+	names := newNameIndex(fsys)
+	sched := newSchedule(results, mode, names)
+	parked := make(map[int]string)
+
+	progress := Progress{Planned: sched.remaining}
+	defer func() {
+		progress.Current = ""
+		r.report(progress)
+	}()
+
+	type job struct {
+		i    int
+		park bool
+		temp string
+		ok   bool
+	}
+	done := make(chan job)
+	running := 0
+	for sched.remaining > 0 {
+		for running < r.parallel() && ctx.Err() == nil {
+			i, park, ok := sched.next()
+			if !ok {
+				break
+			}
+			if !park {
+				progress.Current = results[i].OldPath
+				r.report(progress)
+			}
+			running++
+			go func(j job) {
+				if j.park {
+					j.temp, j.ok = r.park(fsys, names, &results[j.i], mode)
+				} else {
+					r.execute(fsys, names, &results[j.i], mode, j.temp)
+				}
+				done <- j
+			}(job{i: i, park: park, temp: parked[i]})
+		}
+
+		if running == 0 {
+			if err := ctx.Err(); err != nil {
+				r.unpark(fsys, names, results, mode, parked, sched)
+				return results, err
+			}
+			// 剩余的条目互相依赖且无法提前打破：移动其中一个或强制执行
+			i, park := sched.cycle()
+			if !park {
+				sched.force(i)
+				continue
+			}
+			if temp, ok := r.park(fsys, names, &results[i], mode); ok {
+				parked[i] = temp
+				sched.parked(i)
+				continue
+			}
+			progress.count(results[i].Status)
+			sched.abandon(i)
+			continue
+		}
+
+		j := <-done
+		running--
+		switch {
+		case j.park && j.ok:
+			parked[j.i] = j.temp
+			sched.parked(j.i)
+		default:
+			progress.count(results[j.i].Status)
+			if j.park {
+				sched.abandon(j.i)
+			} else {
+				sched.finish(j.i)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// preview returns a copy of r that executes against an in-memory overlay of
+// its filesystem, used to simulate DryRun batches
+func (r *ReNamer) preview() *ReNamer {
+	p := r.clone()
+	p.DryRun = false
+	p.fs = newOverlayFS(r.filesystem())
+	return p
+}
+
+// selected reports whether mode executes the mapping
+func selected(mapping ReNameResult, mode ReNameMode) bool {
+	switch mode {
+	case ModeNormal:
+		return mapping.Status != StatusError && mapping.Status != StatusSkipped
+	case ModeError:
+		return mapping.Status == StatusError
+	default:
+		return true
+	}
+}
+
+// effective returns the mapping as it is executed in mode
+func effective(mapping ReNameResult, mode ReNameMode) ReNameResult {
+	if mode == ModeUndo {
+		// 执行回退操作，交换新旧路径
+		mapping.OldPath, mapping.NewPath = mapping.NewPath, mapping.OldPath
+	}
+	return mapping
+}
+
+// verify checks a mapping before anything is renamed
+func (r *ReNamer) verify(mapping ReNameResult, mode ReNameMode) error {
+	// 检查路径有效性
+	if mapping.OldPath == "" || mapping.NewPath == "" {
+		return newError(ErrInvalidPath, mapping.OldPath, "无效的文件路径")
+	}
+
+	// 检查目标文件名在目标平台上是否合法
+	if mode != ModeUndo {
+		if err := ValidateName(filepath.Base(mapping.NewPath), r.Platform); err != nil {
+			return err
+		}
+	}
+
+	// 检查目标是否越出沙箱
+	return r.validateMapping(mapping.OldPath, mapping.NewPath)
+}
+
+// renameFailed wraps an error returned by the filesystem
+func renameFailed(path string, err error) *Error {
+	return &Error{Kind: ErrRenameFailed, Path: path, Msg: fmt.Sprintf("Rename failed: %v", err), Err: err}
+}
+
+// execute performs a single mapping and records the outcome in result. A
+// non-empty parked is the temporary name the source was moved to in order to
+// break a cycle; it is moved back if the entry cannot be completed.
+func (r *ReNamer) execute(fsys FS, names *nameIndex, result *ReNameResult, mode ReNameMode, parked string) {
+	mapping := effective(*result, mode)
+	if parked == "" {
+		if err := r.verify(mapping, mode); err != nil {
+			result.fail(err)
+			return
+		}
+
+		// 如果新旧路径相同，标记为成功并跳过
+		if mapping.OldPath == mapping.NewPath {
+			result.Status = StatusSuccess // 使用枚举值
+			return
+		}
+	} else {
+		source := mapping.OldPath
+		mapping.OldPath = parked
+		defer func() {
+			if result.Status != StatusSuccess && fsys.Rename(parked, source) == nil {
+				names.renamed(parked, source)
+			}
+		}()
+	}
+
+	// 检查目标是否已存在，避免覆盖其他文件
+	if err := names.checkTarget(mapping.OldPath, mapping.NewPath); err != nil {
+		if r.ConflictPolicy == ConflictSkip {
+			result.skip(err)
+		} else {
+			result.fail(err)
+		}
+		return
+	}
+
+	var err error
+	if r.AllowMove {
+		// 允许移动时自动创建目标目录
+		if err := mkdirAll(fsys, filepath.Dir(mapping.NewPath)); err != nil {
+			result.fail(renameFailed(mapping.OldPath, err))
+			return
+		}
+	}
+	if isCaseOnlyChange(mapping.OldPath, mapping.NewPath) && names.insensitive(filepath.Dir(mapping.NewPath)) {
+		// 大小写不敏感的文件系统上需要通过临时文件名完成仅大小写的修改
+		err = renameCaseOnly(fsys, mapping.OldPath, mapping.NewPath)
+	} else {
+		err = fsys.Rename(mapping.OldPath, mapping.NewPath)
+	}
+	if err != nil {
+		result.fail(renameFailed(mapping.OldPath, err))
+	} else {
+		result.Status = StatusSuccess
+		result.Err = nil
+		names.renamed(mapping.OldPath, mapping.NewPath)
+	}
+}
+
+// park moves the source of a mapping in a dependency cycle to a temporary
+// name in its directory, so that the entry waiting for the source name can
+// run. It returns false and marks the result as failed if that is not
+// possible.
+func (r *ReNamer) park(fsys FS, names *nameIndex, result *ReNameResult, mode ReNameMode) (string, bool) {
+	mapping := effective(*result, mode)
+	if err := r.verify(mapping, mode); err != nil {
+		result.fail(err)
+		return "", false
+	}
+	temp := filepath.Join(filepath.Dir(mapping.OldPath), ".renaming-"+uuid.New().String())
+	if err := fsys.Rename(mapping.OldPath, temp); err != nil {
+		result.fail(renameFailed(mapping.OldPath, err))
+		return "", false
+	}
+	names.renamed(mapping.OldPath, temp)
+	return temp, true
+}
+
+// unpark moves parked entries that were not started back to their names
+// when the batch is cancelled
+func (r *ReNamer) unpark(fsys FS, names *nameIndex, results []ReNameResult, mode ReNameMode, parked map[int]string, sched *schedule) {
+	for i, temp := range parked {
+		if !sched.waiting[i] {
+			continue
+		}
+		source := effective(results[i], mode).OldPath
+		if fsys.Rename(temp, source) == nil {
+			names.renamed(temp, source)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package renamer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDirectoryRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string // 创建的文件
+		batch    []string // 批次中的路径
+		wantTree []string
+	}{
+		{"directory name keeps dots", []string{"/p/album.v1/a.jpg"}, []string{"/p/album.v1"},
+			[]string{"/", "/p", "/p/album.v1_new", "/p/album.v1_new/a.jpg"}},
+		{"directory with its entries", []string{"/p/album.v1/a.jpg", "/p/album.v1/b.jpg"},
+			[]string{"/p/album.v1", "/p/album.v1/a.jpg", "/p/album.v1/b.jpg"},
+			[]string{"/", "/p", "/p/album.v1_new", "/p/album.v1_new/a_new.jpg", "/p/album.v1_new/b_new.jpg"}},
+		{"nested directories", []string{"/p/x/y/f.txt"}, []string{"/p/x", "/p/x/y", "/p/x/y/f.txt"},
+			[]string{"/", "/p", "/p/x_new", "/p/x_new/y_new", "/p/x_new/y_new/f_new.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, tt.files...)
+			r := New(WithFS(fsys), WithRules(Rule{Pattern: "$", Replace: "_new"}))
+			r.AddFiles(tt.batch)
+			for _, result := range r.ApplyBatch() {
+				if result.Status != StatusSuccess {
+					t.Errorf("%s: status %s (%s)", result.OldPath, result.Status, result.Message)
+				}
+			}
+			if got := listTree(t, fsys, "/"); !reflect.DeepEqual(got, tt.wantTree) {
+				t.Errorf("tree = %v, want %v", got, tt.wantTree)
+			}
+		})
+	}
+}
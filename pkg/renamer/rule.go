@@ -0,0 +1,68 @@
+package renamer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Rule 重命名规则
+type Rule struct {
+	ID      string            `json:"id"`                // 唯一标识符
+	Name    string            `json:"name"`              // 操作名称
+	Type    string            `json:"type,omitempty"`    // 规则类型，为空时表示正则替换
+	Pattern string            `json:"pattern"`           // 匹配模式
+	Replace string            `json:"Replace"`           // 替换模板
+	Options map[string]string `json:"options,omitempty"` // 规则类型的附加参数
+}
+
+// RuleContext 规则执行时的上下文信息
+type RuleContext struct {
+	Index int               // 文件在批次中的序号，从 0 开始
+	Path  string            // 原始文件路径
+	Vars  map[string]string // 命名捕获组等模板变量，在规则链中向后传递
+
+	fs      FS              // 文件所在的文件系统，为空时使用本地文件系统
+	regexps *regexpCache    // 批次内共享的正则表达式缓存
+	batch   context.Context // 批次的上下文，取消时终止外部命令，为空时不会被取消
+
+	allowExec bool // 是否允许 exec 规则执行外部命令
+}
+
+// Apply 应用规则到文件名，返回新文件名和错误
+func (r Rule) Apply(filename string) (string, error) {
+	return r.ApplyContext(filename, &RuleContext{})
+}
+
+// ApplyContext 在给定上下文中应用规则到文件名
+func (r Rule) ApplyContext(filename string, ctx *RuleContext) (string, error) {
+	if r.Type != "" && r.Type != RuleTypeRegex {
+		transform, ok := lookupTransform(r.Type)
+		if !ok {
+			return filename, newError(ErrUnknownRuleType, "", "未知的规则类型 '%s'", r.Type)
+		}
+		return transform(r, filename, ctx)
+	}
+
+	// 安全地编译正则表达式，批次内只编译一次
+	re, err := ctx.compile(r.Pattern)
+	if err != nil {
+		return filename, fmt.Errorf("无效的正则表达式 '%s': %v", r.Pattern, err)
+	}
+
+	// 逐个匹配展开模板，命名捕获组可以在 {name|filter} 占位符中引用
+	var sb strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(filename, -1) {
+		captureGroups(re, filename, m, ctx)
+		expanded, err := expandTemplate(r.Replace, ctx, true)
+		if err != nil {
+			return filename, err
+		}
+		sb.WriteString(filename[last:m[0]])
+		sb.Write(re.ExpandString(nil, expanded, filename, m))
+		last = m[1]
+	}
+	sb.WriteString(filename[last:])
+	return sb.String(), nil
+}
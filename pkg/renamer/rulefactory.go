@@ -0,0 +1,416 @@
+package renamer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RuleFactory 规则工厂，用于创建各种重命名规则
+type RuleFactory struct{}
+
+// NewRuleFactory 创建新的规则工厂
+func NewRuleFactory() *RuleFactory {
+	return &RuleFactory{}
+}
+
+// AddPrefix 添加前缀
+func (rf *RuleFactory) AddPrefix(prefix string) Rule {
+	return Rule{
+		Name:    "AddPrefix",
+		Pattern: `^`,
+		Replace: prefix,
+	}
+}
+
+// AddSuffix 添加后缀
+func (rf *RuleFactory) AddSuffix(suffix string) Rule {
+	return Rule{
+		Name:    "AddSuffix",
+		Pattern: `$`,
+		Replace: suffix,
+	}
+}
+
+// AddAfterPattern 在匹配模式后添加内容
+func (rf *RuleFactory) AddAfterPattern(pattern, content string) Rule {
+	return Rule{
+		Name:    "AddAfterPattern",
+		Pattern: `(` + pattern + `)`,
+		Replace: "${1}" + content,
+	}
+}
+
+// AddBeforePattern 在匹配模式前添加内容
+func (rf *RuleFactory) AddBeforePattern(pattern, content string) Rule {
+	return Rule{
+		Name:    "AddBeforePattern",
+		Pattern: `(` + pattern + `)`,
+		Replace: content + "${1}",
+	}
+}
+
+// AddAtPosition 在指定位置添加内容
+func (rf *RuleFactory) AddAtPosition(pos int, content string) Rule {
+	return Rule{
+		Name:    "AddAtPosition",
+		Pattern: fmt.Sprintf(`^(.{%d})(.*)$`, pos),
+		Replace: "${1}" + content + "${2}",
+	}
+}
+
+// AddBeforeLastN 在末尾N个字符前添加内容
+func (rf *RuleFactory) AddBeforeLastN(n int, content string) Rule {
+	return Rule{
+		Name:    "AddBeforeLastN",
+		Pattern: fmt.Sprintf(`^(.*?)(.{%d})$`, n),
+		Replace: "${1}" + content + "${2}",
+	}
+}
+
+// RemovePattern 删除匹配的模式
+func (rf *RuleFactory) RemovePattern(pattern string) Rule {
+	return Rule{
+		Name:    "RemovePattern",
+		Pattern: pattern,
+		Replace: "",
+	}
+}
+
+// RemoveNumbers 删除数字
+func (rf *RuleFactory) RemoveNumbers() Rule {
+	return Rule{
+		Name:    "RemoveNumbers",
+		Pattern: `\d+`,
+		Replace: "",
+	}
+}
+
+// RemoveSpaces 删除空格
+func (rf *RuleFactory) RemoveSpaces() Rule {
+	return Rule{
+		Name:    "RemoveSpaces",
+		Pattern: `\s+`,
+		Replace: "",
+	}
+}
+
+// RemoveLetters 删除字母
+func (rf *RuleFactory) RemoveLetters() Rule {
+	return Rule{
+		Name:    "RemoveLetters",
+		Pattern: `[a-zA-Z]+`,
+		Replace: "",
+	}
+}
+
+// RemoveAtPosition 删除指定位置的字符
+func (rf *RuleFactory) RemoveAtPosition(pos int) Rule {
+	return Rule{
+		Name:    "RemoveAtPosition",
+		Pattern: fmt.Sprintf(`^(.{%d}).(.*)$`, pos),
+		Replace: "${1}${2}",
+	}
+}
+
+// RemoveFromEnd 从末尾删除N个字符
+func (rf *RuleFactory) RemoveFromEnd(n int) Rule {
+	return Rule{
+		Name:    "RemoveFromEnd",
+		Pattern: fmt.Sprintf(`^(.*?)(.{%d})$`, n),
+		Replace: "${1}",
+	}
+}
+
+// RemoveRange 删除指定范围的字符
+func (rf *RuleFactory) RemoveRange(start, end int) Rule {
+	return Rule{
+		Name:    "RemoveRange",
+		Pattern: fmt.Sprintf(`^(.{%d}).{%d}(.*)$`, start, end-start),
+		Replace: "${1}${2}",
+	}
+}
+
+// RemoveBetweenDelimiters 删除两个分隔符之间的内容，保留分隔符
+func (rf *RuleFactory) RemoveBetweenDelimiters(startDelim, endDelim string) Rule {
+	return Rule{
+		Name:    "RemoveBetweenDelimiters",
+		Pattern: startDelim + `[^` + endDelim + `]*` + endDelim,
+		Replace: startDelim + endDelim,
+	}
+}
+
+// RemoveWithDelimiters 删除两个分隔符之间的内容，包括分隔符
+func (rf *RuleFactory) RemoveWithDelimiters(startDelim, endDelim string) Rule {
+	return Rule{
+		Name:    "RemoveWithDelimiters",
+		Pattern: startDelim + `[^` + endDelim + `]*` + endDelim,
+		Replace: "",
+	}
+}
+
+// ReplacePattern 替换匹配的模式
+func (rf *RuleFactory) ReplacePattern(oldPattern, newPattern string) Rule {
+	return Rule{
+		Name:    "ReplacePattern",
+		Pattern: oldPattern,
+		Replace: newPattern,
+	}
+}
+
+// ReplaceSpaces 替换空格
+func (rf *RuleFactory) ReplaceSpaces(replacement string) Rule {
+	return Rule{
+		Name:    "ReplaceSpaces",
+		Pattern: `\s+`,
+		Replace: replacement,
+	}
+}
+
+// ReplaceNumbers 替换数字
+func (rf *RuleFactory) ReplaceNumbers(replacement string) Rule {
+	return Rule{
+		Name:    "ReplaceNumbers",
+		Pattern: `\d`,
+		Replace: replacement,
+	}
+}
+
+// ReplaceLetters 替换字母
+func (rf *RuleFactory) ReplaceLetters(replacement string) Rule {
+	return Rule{
+		Name:    "ReplaceLetters",
+		Pattern: `[a-zA-Z]`,
+		Replace: replacement,
+	}
+}
+
+// ReplaceAtPosition 替换指定位置的字符
+func (rf *RuleFactory) ReplaceAtPosition(pos int, replacement string) Rule {
+	return Rule{
+		Name:    "ReplaceAtPosition",
+		Pattern: fmt.Sprintf(`^(.{%d}).(.*)$`, pos),
+		Replace: "${1}" + replacement + "${2}",
+	}
+}
+
+// ReplaceRange 替换指定范围的字符
+func (rf *RuleFactory) ReplaceRange(start, end int, replacement string) Rule {
+	return Rule{
+		Name:    "ReplaceRange",
+		Pattern: fmt.Sprintf(`^(.{%d}).{%d}(.*)$`, start, end-start),
+		Replace: "${1}" + replacement + "${2}",
+	}
+}
+
+// ReplaceBetweenDelimiters 替换两个分隔符之间的内容
+func (rf *RuleFactory) ReplaceBetweenDelimiters(startDelim, endDelim, replacement string) Rule {
+	return Rule{
+		Name:    "ReplaceBetweenDelimiters",
+		Pattern: startDelim + `[^` + endDelim + `]*` + endDelim,
+		Replace: startDelim + replacement + endDelim,
+	}
+}
+
+// Sanitize 将文件名中目标平台不允许的字符替换为 replacement
+func (rf *RuleFactory) Sanitize(platform Platform, replacement string) Rule {
+	return Rule{
+		Name: "Sanitize",
+		Type: RuleTypeSanitize,
+		Options: map[string]string{
+			"platform":    string(platform),
+			"replacement": replacement,
+		},
+	}
+}
+
+// Normalize 将文件名转换为指定的 Unicode 规范化形式（NFC、NFD、NFKC 或 NFKD）
+func (rf *RuleFactory) Normalize(form string) Rule {
+	return Rule{
+		Name:    "Normalize",
+		Type:    RuleTypeNormalize,
+		Options: map[string]string{"form": form},
+	}
+}
+
+// Pinyin 将汉字转换为拼音，tone 为 ToneNone、ToneMark 或 ToneNumber，
+// separator 插入在相邻汉字的拼音之间，initialsOnly 为 true 时只保留首字母
+// 多音字按内置词表识别常见词语（如“重庆”“银行”），词表之外按最常见的单字读音转换
+func (rf *RuleFactory) Pinyin(tone, separator string, initialsOnly bool) Rule {
+	return Rule{
+		Name: "Pinyin",
+		Type: RuleTypePinyin,
+		Options: map[string]string{
+			"tone":      tone,
+			"separator": separator,
+			"initials":  strconv.FormatBool(initialsOnly),
+		},
+	}
+}
+
+// Transliterate 将带重音的拉丁字母、西里尔字母、希腊字母和日文假名转写为 ASCII，
+// cyrillic 和 kana 选择转写方案，为空时使用 CyrillicBGN 和 KanaHepburn
+func (rf *RuleFactory) Transliterate(cyrillic, kana string) Rule {
+	return Rule{
+		Name: "Transliterate",
+		Type: RuleTypeTranslit,
+		Options: map[string]string{
+			"cyrillic": cyrillic,
+			"kana":     kana,
+		},
+	}
+}
+
+// TransliterateStrict 转写为 ASCII 后将不在 allowed 中的字符替换为 replacement。
+// allowed 中的字符按字面处理，为空时保留字母、数字、空格和 ._-
+func (rf *RuleFactory) TransliterateStrict(allowed, replacement string) Rule {
+	options := map[string]string{
+		"strip":       "true",
+		"replacement": replacement,
+	}
+	if allowed != "" {
+		options["allowed"] = quoteClass(allowed)
+	}
+	return Rule{
+		Name:    "TransliterateStrict",
+		Type:    RuleTypeTranslit,
+		Options: options,
+	}
+}
+
+// quoteClass 转义字符，使其可以按字面放入正则表达式的字符类中
+func quoteClass(chars string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(chars), "-", `\-`)
+}
+
+// ToTraditional 简体中文转繁体中文
+func (rf *RuleFactory) ToTraditional() Rule {
+	return Rule{
+		Name:    "ToTraditional",
+		Type:    RuleTypeChinese,
+		Options: map[string]string{"to": ChineseTraditional},
+	}
+}
+
+// ToSimplified 繁体中文转简体中文
+func (rf *RuleFactory) ToSimplified() Rule {
+	return Rule{
+		Name:    "ToSimplified",
+		Type:    RuleTypeChinese,
+		Options: map[string]string{"to": ChineseSimplified},
+	}
+}
+
+// ToHalfWidth 全角字符转半角字符
+func (rf *RuleFactory) ToHalfWidth() Rule {
+	return Rule{
+		Name:    "ToHalfWidth",
+		Type:    RuleTypeWidth,
+		Options: map[string]string{"to": WidthHalf},
+	}
+}
+
+// ToFullWidth 半角字符转全角字符
+func (rf *RuleFactory) ToFullWidth() Rule {
+	return Rule{
+		Name:    "ToFullWidth",
+		Type:    RuleTypeWidth,
+		Options: map[string]string{"to": WidthFull},
+	}
+}
+
+// PadNumbers 将文件名中的数字补零到 width 位
+func (rf *RuleFactory) PadNumbers(width int) Rule {
+	return Rule{
+		Name: "PadNumbers",
+		Type: RuleTypeNumber,
+		Options: map[string]string{
+			"mode":  NumberPad,
+			"width": strconv.Itoa(width),
+		},
+	}
+}
+
+// UnpadNumbers 去掉文件名中数字的前导零
+func (rf *RuleFactory) UnpadNumbers() Rule {
+	return Rule{
+		Name:    "UnpadNumbers",
+		Type:    RuleTypeNumber,
+		Options: map[string]string{"mode": NumberUnpad},
+	}
+}
+
+// OffsetNumbers 将文件名中的数字加上 offset，保持原有位数
+func (rf *RuleFactory) OffsetNumbers(offset int) Rule {
+	return Rule{
+		Name: "OffsetNumbers",
+		Type: RuleTypeNumber,
+		Options: map[string]string{
+			"mode":   NumberOffset,
+			"offset": strconv.Itoa(offset),
+		},
+	}
+}
+
+// RenumberNumbers 按文件在批次中的顺序重新编号 pattern 匹配的数字，
+// 编号为 start + 序号*step，补零到 width 位
+func (rf *RuleFactory) RenumberNumbers(pattern string, start, step, width int) Rule {
+	return Rule{
+		Name:    "RenumberNumbers",
+		Type:    RuleTypeNumber,
+		Pattern: pattern,
+		Options: map[string]string{
+			"mode":  NumberRenumber,
+			"start": strconv.Itoa(start),
+			"step":  strconv.Itoa(step),
+			"width": strconv.Itoa(width),
+		},
+	}
+}
+
+// ReformatDate 识别文件名中的日期（如 20240315、15-03-2024、Mar 15 2024、2024年3月15日），
+// 改写为 format 格式（支持 YYYY、YY、MMMM、MMM、MM、M、DD、D），
+// order 为 DateOrderDMY 或 DateOrderMDY，决定纯数字日期中日和月的顺序
+func (rf *RuleFactory) ReformatDate(format, order string) Rule {
+	return Rule{
+		Name: "ReformatDate",
+		Type: RuleTypeDate,
+		Options: map[string]string{
+			"format": format,
+			"order":  order,
+		},
+	}
+}
+
+// MediaName 解析场景发布风格的媒体文件名，剧集按 episodeTemplate、电影按 movieTemplate 输出，
+// 模板可使用 {show}、{season}、{episode}、{title}、{year}、{resolution}
+func (rf *RuleFactory) MediaName(episodeTemplate, movieTemplate string) Rule {
+	rule := Rule{
+		Name:    "MediaName",
+		Type:    RuleTypeMedia,
+		Replace: episodeTemplate,
+	}
+	if movieTemplate != "" {
+		rule.Options = map[string]string{"movie": movieTemplate}
+	}
+	return rule
+}
+
+// Expression 以表达式计算新文件名，可使用 name、ext、index、size、modtime 等变量
+func (rf *RuleFactory) Expression(expr string) Rule {
+	return Rule{
+		Name:    "Expression",
+		Type:    RuleTypeExpr,
+		Replace: expr,
+	}
+}
+
+// ExternalCommand 通过外部命令批量计算新文件名，args 以空白分隔
+func (rf *RuleFactory) ExternalCommand(command, args string) Rule {
+	return Rule{
+		Name:    "ExternalCommand",
+		Type:    RuleTypeExec,
+		Options: map[string]string{"command": command, "args": args},
+	}
+}
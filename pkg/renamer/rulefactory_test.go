@@ -0,0 +1,36 @@
+package renamer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransliterateStrict(t *testing.T) {
+	tests := []struct {
+		name, allowed, replacement string
+		want                       string
+	}{
+		{"Привет мир!.txt", "", "_", "Privet mir_.txt"},
+		{"a^b]c-d.txt", "", "", "abc-d.txt"},
+		{"a^b]c-d.txt", "^]", "", "^].txt"},
+		{"a-z_b.txt", "a-z", "", "a-z.txt"},
+		{`a\b(c).txt`, `\()`, "", `\().txt`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.allowed, func(t *testing.T) {
+			rule := NewRuleFactory().TransliterateStrict(tt.allowed, tt.replacement)
+			r := New(WithFS(newMemTree(t, "/d/"+tt.name)), WithRules(rule))
+			mappings, err := r.generateMappings(context.Background(), []string{"/d/" + tt.name}, 0)
+			if err != nil {
+				t.Fatalf("generateMappings: %v", err)
+			}
+			if len(mappings) != 1 {
+				t.Fatalf("got %d mappings", len(mappings))
+			}
+			if got := filepath.Base(mappings[0].NewPath); got != tt.want {
+				t.Errorf("got %q, want %q (%s)", got, tt.want, mappings[0].Message)
+			}
+		})
+	}
+}
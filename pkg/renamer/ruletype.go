@@ -0,0 +1,43 @@
+package renamer
+
+// 规则类型
+const (
+	RuleTypeRegex     = "regex"     // 正则替换（默认）
+	RuleTypeSanitize  = "sanitize"  // 清理目标平台上的非法文件名
+	RuleTypeNormalize = "normalize" // Unicode 规范化（NFC/NFD/NFKC/NFKD）
+	RuleTypePinyin    = "pinyin"    // 汉字转拼音
+	RuleTypeTranslit  = "translit"  // 拉丁、西里尔、希腊字母和假名转写为 ASCII
+	RuleTypeChinese   = "chinese"   // 简繁转换
+	RuleTypeWidth     = "width"     // 全角半角转换
+	RuleTypeNumber    = "number"    // 文件名中数字的补零、去零、偏移和重新编号
+	RuleTypeDate      = "date"      // 识别文件名中的日期并改写格式
+	RuleTypeMedia     = "media"     // 解析剧集和电影文件名并按模板输出
+	RuleTypeExpr      = "expr"      // 以表达式计算新文件名
+	RuleTypeExec      = "exec"      // 通过外部命令批量计算新文件名
+)
+
+// ruleTransform 非正则规则的处理函数
+type ruleTransform func(r Rule, filename string, ctx *RuleContext) (string, error)
+
+// ruleTransforms 规则类型与处理函数的映射，包括通过 RegisterRuleKind 注册的类型
+var ruleTransforms = map[string]ruleTransform{
+	RuleTypeSanitize:  applySanitize,
+	RuleTypeNormalize: applyNormalize,
+	RuleTypePinyin:    applyPinyin,
+	RuleTypeTranslit:  applyTransliterate,
+	RuleTypeChinese:   applyChinese,
+	RuleTypeWidth:     applyWidth,
+	RuleTypeNumber:    applyNumber,
+	RuleTypeDate:      applyDate,
+	RuleTypeMedia:     applyMedia,
+	RuleTypeExpr:      applyExpr,
+	RuleTypeExec:      applyExec,
+}
+
+// option 读取规则参数，未设置时返回默认值
+func (r Rule) option(key, def string) string {
+	if v, ok := r.Options[key]; ok {
+		return v
+	}
+	return def
+}
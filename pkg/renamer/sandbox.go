@@ -0,0 +1,130 @@
+package renamer
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// SetRoot 设置沙箱根目录，为空时不限制目标位置
+func (r *ReNamer) SetRoot(root string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Root = root
+}
+
+// SetAllowMove 设置是否允许将文件移动到其他目录
+func (r *ReNamer) SetAllowMove(allow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.AllowMove = allow
+}
+
+// validateName checks a generated file name before it is joined to its directory
+func (r *ReNamer) validateName(name string) error {
+	if name == "." || name == ".." {
+		return newError(ErrInvalidName, name, "生成的文件名 %q 不允许使用", name)
+	}
+	if !r.AllowMove && containsSeparator(name) {
+		return newError(ErrInvalidName, name, "生成的文件名 %q 包含路径分隔符", name)
+	}
+
+	// 允许移动时逐个校验路径分量
+	for _, part := range strings.FieldsFunc(name, isSeparator) {
+		if part == "." || part == ".." {
+			continue // 由沙箱检查决定是否允许
+		}
+		if err := ValidateName(part, r.Platform); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMapping checks that a rename from oldPath to newPath stays inside
+// the sandbox root and, unless moving is allowed, inside the same directory.
+func (r *ReNamer) validateMapping(oldPath, newPath string) error {
+	if !r.AllowMove && filepath.Dir(filepath.Clean(oldPath)) != filepath.Dir(filepath.Clean(newPath)) {
+		return newError(ErrMoveNotAllowed, newPath, "目标 %s 与 %s 不在同一目录", newPath, oldPath)
+	}
+
+	if r.Root == "" {
+		return nil
+	}
+
+	root, err := r.resolvePath(r.Root)
+	if err != nil {
+		return newError(ErrInvalidPath, r.Root, "无效的沙箱根目录 %s: %v", r.Root, err)
+	}
+	if !r.isInsideRoot(root, oldPath) {
+		return newError(ErrOutsideRoot, oldPath, "源 %s 在沙箱根目录 %s 之外", oldPath, r.Root)
+	}
+	if !r.isInsideRoot(root, newPath) {
+		return newError(ErrOutsideRoot, newPath, "目标 %s 在沙箱根目录 %s 之外", newPath, r.Root)
+	}
+	return nil
+}
+
+// isInsideRoot reports whether path lies strictly below root. The path is
+// resolved through symlinks up to its longest existing ancestor, so that a
+// link inside the root cannot be used to reach entries outside of it; paths
+// that cannot be resolved are rejected.
+func (r *ReNamer) isInsideRoot(root, path string) bool {
+	abs, err := r.resolveExisting(path)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return false
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return !filepath.IsAbs(rel)
+}
+
+// resolveExisting returns the absolute form of path with its longest existing
+// ancestor directory resolved through symlinks and the missing parts appended.
+// The last element itself is not resolved, it is the entry being renamed.
+func (r *ReNamer) resolveExisting(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir, missing := filepath.Dir(abs), []string{filepath.Base(abs)}
+	for {
+		resolved, err := r.filesystem().EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		parent := filepath.Dir(dir)
+		if !errors.Is(err, fs.ErrNotExist) || parent == dir {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+		dir = parent
+	}
+}
+
+// resolvePath returns the absolute, symlink-free form of path
+func (r *ReNamer) resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return r.filesystem().EvalSymlinks(abs)
+}
+
+// containsSeparator reports whether name contains a path separator.
+// '/' is always treated as a separator, even on Windows.
+func containsSeparator(name string) bool {
+	return strings.IndexFunc(name, isSeparator) >= 0
+}
+
+func isSeparator(c rune) bool {
+	return c == '/' || c == filepath.Separator
+}
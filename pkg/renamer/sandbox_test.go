@@ -0,0 +1,66 @@
+package renamer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSandboxRoot(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		err    error // nil means the rename succeeds
+	}{
+		{"same directory", "b.txt", nil},
+		{"new directories inside root", "new/sub/b.txt", nil},
+		{"existing link to outside", "link/b.txt", ErrOutsideRoot},
+		{"missing directories below link", "link/newdir/b.txt", ErrOutsideRoot},
+		{"parent traversal", "../outside/b.txt", ErrOutsideRoot},
+		{"root itself", ".", ErrOutsideRoot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := filepath.Join(root, "a.txt")
+			if err := os.WriteFile(source, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(source)
+
+			target := filepath.Join(root, tt.target)
+			r := New(WithRoot(root), WithAllowMove(true))
+			results := r.ApplyMapping([]ReNameResult{{OldPath: source, NewPath: target}}, ModeNormal)
+			result := results[0]
+
+			if tt.err == nil {
+				if result.Status != StatusSuccess {
+					t.Fatalf("status %s (%s), want success", result.Status, result.Message)
+				}
+				os.Remove(target)
+				return
+			}
+			if !errors.Is(result.Err, tt.err) {
+				t.Fatalf("err = %v, want %v", result.Err, tt.err)
+			}
+			if _, err := os.Stat(source); err != nil {
+				t.Errorf("source was moved: %v", err)
+			}
+			if entries, _ := os.ReadDir(outside); len(entries) > 0 {
+				t.Errorf("entries created outside the root: %v", entries)
+			}
+		})
+	}
+}
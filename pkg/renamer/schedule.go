@@ -0,0 +1,370 @@
+package renamer
+
+import (
+	"container/heap"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// scheduleEdge makes the entry to wait for the entry that owns the edge
+type scheduleEdge struct {
+	to   int
+	name bool // to waits for the source name of the owner to become free
+	done bool
+}
+
+// schedule orders the execution of a batch. An entry becomes ready once the
+// entries it depends on have finished:
+//   - an entry whose target is the source of another entry waits until that
+//     entry has moved away, so chains run back to front;
+//   - entries inside a renamed directory run before the directory is renamed,
+//     and after it has been restored when undoing, because their paths use
+//     the name the directory had when the batch was planned;
+//   - entries with the same target run one after another.
+//
+// Ready entries are started in execution order (deepest first, shallowest
+// first for undo), so a batch without dependencies runs in the same order as
+// before. Cycles such as a→b, b→a are broken by parking one entry of the
+// cycle under a temporary name as soon as it only waits for names, so that
+// independent cycles proceed in parallel.
+type schedule struct {
+	rank      []int            // position of each mapping in the execution order
+	deps      []int            // number of unfinished dependencies
+	nameDeps  []int            // number of unfinished name dependencies
+	edges     [][]scheduleEdge // dependents of each mapping
+	breaker   []bool           // entry is parked to break a cycle of names
+	waiting   map[int]bool     // selected entries that have not been started
+	ready     readyQueue
+	parkable  []int // breakers that only wait for names
+	remaining int   // selected entries that have not finished
+}
+
+// newSchedule builds the dependency graph of the entries selected by mode
+func newSchedule(mappings []ReNameResult, mode ReNameMode, names *nameIndex) *schedule {
+	s := &schedule{
+		rank:     make([]int, len(mappings)),
+		deps:     make([]int, len(mappings)),
+		nameDeps: make([]int, len(mappings)),
+		edges:    make([][]scheduleEdge, len(mappings)),
+		breaker:  make([]bool, len(mappings)),
+		waiting:  make(map[int]bool),
+	}
+	s.ready.rank = s.rank
+
+	var nodes []int
+	for pos, i := range executionOrder(mappings, mode) {
+		s.rank[i] = pos
+		if selected(mappings[i], mode) {
+			nodes = append(nodes, i)
+		}
+	}
+	s.remaining = len(nodes)
+
+	// 按执行时的新旧路径建立索引
+	src := make(map[int]string, len(nodes))
+	dst := make(map[int]string, len(nodes))
+	bySource := make(map[string][]int)
+	byTarget := make(map[string][]int)
+	for _, i := range nodes {
+		m := effective(mappings[i], mode)
+		if m.OldPath == "" || m.NewPath == "" {
+			continue
+		}
+		src[i], dst[i] = m.OldPath, m.NewPath
+		if m.OldPath == m.NewPath {
+			continue
+		}
+		bySource[names.key(m.OldPath)] = append(bySource[names.key(m.OldPath)], i)
+		byTarget[names.key(m.NewPath)] = append(byTarget[names.key(m.NewPath)], i)
+	}
+
+	// 目标是其他条目的源：等待其先移走
+	for _, i := range nodes {
+		if path, ok := dst[i]; ok {
+			for _, j := range bySource[names.key(path)] {
+				if j != i {
+					s.depend(j, i, true)
+				}
+			}
+		}
+	}
+	// 目标相同的条目依次执行
+	for _, group := range byTarget {
+		for k := 1; k < len(group); k++ {
+			s.depend(group[k-1], group[k], false)
+		}
+	}
+
+	// 目录中的条目：普通模式先于目录执行，回退模式在目录恢复后执行
+	dirs, before := bySource, true
+	if mode == ModeUndo {
+		dirs, before = byTarget, false
+	}
+	candidates := make(map[string]bool, len(dirs))
+	for _, group := range dirs {
+		for _, i := range group {
+			if before {
+				candidates[foldPath(src[i])] = true
+			} else {
+				candidates[foldPath(dst[i])] = true
+			}
+		}
+	}
+	for _, i := range nodes {
+		for _, path := range []string{src[i], dst[i]} {
+			if path == "" {
+				continue
+			}
+			for _, j := range enclosing(path, candidates, dirs, names) {
+				if j == i {
+					continue
+				}
+				if before {
+					s.depend(i, j, false)
+				} else {
+					s.depend(j, i, false)
+				}
+			}
+		}
+	}
+
+	s.findCycles(nodes)
+	for _, i := range nodes {
+		s.waiting[i] = true
+		if s.deps[i] == 0 {
+			heap.Push(&s.ready, i)
+		} else {
+			s.checkParkable(i)
+		}
+	}
+	return s
+}
+
+// depend makes to wait for from
+func (s *schedule) depend(from, to int, name bool) {
+	s.edges[from] = append(s.edges[from], scheduleEdge{to: to, name: name})
+	s.deps[to]++
+	if name {
+		s.nameDeps[to]++
+	}
+}
+
+// findCycles marks one entry, the first in execution order, of every cycle
+// of name dependencies as a breaker, using Tarjan's strongly connected
+// components algorithm without recursion
+func (s *schedule) findCycles(nodes []int) {
+	const unvisited = -1
+	index := make([]int, len(s.edges))
+	for i := range index {
+		index[i] = unvisited
+	}
+	low := make([]int, len(s.edges))
+	onStack := make([]bool, len(s.edges))
+	var stack []int
+	counter := 0
+	visit := func(v int) {
+		index[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+	}
+
+	type frame struct{ node, edge int }
+	for _, root := range nodes {
+		if index[root] != unvisited {
+			continue
+		}
+		visit(root)
+		calls := []frame{{root, 0}}
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			if f.edge < len(s.edges[f.node]) {
+				e := s.edges[f.node][f.edge]
+				f.edge++
+				if !e.name {
+					continue
+				}
+				if index[e.to] == unvisited {
+					visit(e.to)
+					calls = append(calls, frame{e.to, 0})
+				} else if onStack[e.to] && index[e.to] < low[f.node] {
+					low[f.node] = index[e.to]
+				}
+				continue
+			}
+
+			v := f.node
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				if parent := calls[len(calls)-1].node; low[v] < low[parent] {
+					low[parent] = low[v]
+				}
+			}
+			if low[v] != index[v] {
+				continue
+			}
+			first, size := v, 0
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				size++
+				if s.rank[w] < s.rank[first] {
+					first = w
+				}
+				if w == v {
+					break
+				}
+			}
+			if size > 1 {
+				s.breaker[first] = true
+			}
+		}
+	}
+}
+
+// checkParkable queues breaker i for parking once it only waits for names
+func (s *schedule) checkParkable(i int) {
+	if s.breaker[i] && s.waiting[i] && s.deps[i] > 0 && s.deps[i] == s.nameDeps[i] {
+		s.breaker[i] = false
+		s.parkable = append(s.parkable, i)
+	}
+}
+
+// next returns the next job: a breaker to park, or else the ready entry
+// that comes first in execution order
+func (s *schedule) next() (i int, park bool, ok bool) {
+	for len(s.parkable) > 0 {
+		i = s.parkable[0]
+		s.parkable = s.parkable[1:]
+		if s.waiting[i] && s.deps[i] > 0 {
+			return i, true, true
+		}
+	}
+	if s.ready.Len() == 0 {
+		return 0, false, false
+	}
+	i = heap.Pop(&s.ready).(int)
+	delete(s.waiting, i)
+	return i, false, true
+}
+
+// abandon finishes a waiting entry that will not be executed
+func (s *schedule) abandon(i int) {
+	delete(s.waiting, i)
+	s.finish(i)
+}
+
+// finish records that entry i has finished, successfully or not
+func (s *schedule) finish(i int) {
+	s.remaining--
+	for k := range s.edges[i] {
+		s.release(&s.edges[i][k])
+	}
+}
+
+// release resolves a dependency
+func (s *schedule) release(e *scheduleEdge) {
+	if e.done {
+		return
+	}
+	e.done = true
+	s.deps[e.to]--
+	if e.name {
+		s.nameDeps[e.to]--
+	}
+	if s.deps[e.to] == 0 && s.waiting[e.to] {
+		heap.Push(&s.ready, e.to)
+	} else {
+		s.checkParkable(e.to)
+	}
+}
+
+// cycle is called when entries are waiting but none is ready or running,
+// which only happens for cycles that findCycles did not break. It
+// returns the entry to break the cycle with: park is true if another entry
+// waits for its source name, so that moving it to a temporary name lets the
+// cycle proceed; otherwise the entry must be started regardless of its
+// dependencies.
+func (s *schedule) cycle() (i int, park bool) {
+	best, bestPark := -1, false
+	for j := range s.waiting {
+		hasName := false
+		for _, e := range s.edges[j] {
+			if e.name && !e.done {
+				hasName = true
+				break
+			}
+		}
+		if best < 0 || (hasName && !bestPark) || (hasName == bestPark && s.rank[j] < s.rank[best]) {
+			best, bestPark = j, hasName
+		}
+	}
+	return best, bestPark
+}
+
+// parked records that entry i was moved to a temporary name, which frees
+// its source name for the entries waiting for it
+func (s *schedule) parked(i int) {
+	for k := range s.edges[i] {
+		if s.edges[i][k].name {
+			s.release(&s.edges[i][k])
+		}
+	}
+}
+
+// force makes entry i ready regardless of its dependencies
+func (s *schedule) force(i int) {
+	for j := range s.edges {
+		for k := range s.edges[j] {
+			if e := &s.edges[j][k]; e.to == i && !e.done {
+				e.done = true
+				s.deps[i]--
+				if e.name {
+					s.nameDeps[i]--
+				}
+			}
+		}
+	}
+	heap.Push(&s.ready, i)
+}
+
+// enclosing returns the entries of dirs that rename the nearest enclosing
+// directory of path
+func enclosing(path string, candidates map[string]bool, dirs map[string][]int, names *nameIndex) []int {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if candidates[foldPath(dir)] {
+			if group, ok := dirs[names.key(dir)]; ok {
+				return group
+			}
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return nil
+		}
+	}
+}
+
+// foldPath returns a form of path that is equal for all names that may
+// collide, used to avoid probing directories that cannot match
+func foldPath(path string) string {
+	return strings.ToLower(norm.NFC.String(filepath.Clean(path)))
+}
+
+// readyQueue is a heap of mapping indexes ordered by rank
+type readyQueue struct {
+	items []int
+	rank  []int
+}
+
+func (q readyQueue) Len() int            { return len(q.items) }
+func (q readyQueue) Less(a, b int) bool  { return q.rank[q.items[a]] < q.rank[q.items[b]] }
+func (q readyQueue) Swap(a, b int)       { q.items[a], q.items[b] = q.items[b], q.items[a] }
+func (q *readyQueue) Push(x interface{}) { q.items = append(q.items, x.(int)) }
+func (q *readyQueue) Pop() interface{} {
+	n := len(q.items)
+	x := q.items[n-1]
+	q.items = q.items[:n-1]
+	return x
+}
@@ -0,0 +1,91 @@
+package renamer
+
+import (
+	"io"
+	"testing"
+)
+
+// newTaggedTree returns a MemFS in which every file contains its own path
+func newTaggedTree(t *testing.T, files ...string) *MemFS {
+	t.Helper()
+	fsys := newMemTree(t, files...)
+	for _, file := range files {
+		if err := fsys.WriteFile(file, []byte(file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fsys
+}
+
+// readTag returns the content of a file written by newTaggedTree
+func readTag(t *testing.T, fsys FS, path string) string {
+	t.Helper()
+	f, err := fsys.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", path, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("Read(%s): %v", path, err)
+	}
+	return string(data)
+}
+
+func TestScheduleOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		mappings [][2]string
+		want     map[string]string // 执行后的路径 → 原路径
+	}{
+		{"chain", []string{"/d/a", "/d/b", "/d/c"},
+			[][2]string{{"/d/a", "/d/b"}, {"/d/b", "/d/c"}, {"/d/c", "/d/d"}},
+			map[string]string{"/d/b": "/d/a", "/d/c": "/d/b", "/d/d": "/d/c"}},
+		{"swap", []string{"/d/a", "/d/b"},
+			[][2]string{{"/d/a", "/d/b"}, {"/d/b", "/d/a"}},
+			map[string]string{"/d/a": "/d/b", "/d/b": "/d/a"}},
+		{"rotation", []string{"/d/1", "/d/2", "/d/3"},
+			[][2]string{{"/d/1", "/d/2"}, {"/d/2", "/d/3"}, {"/d/3", "/d/1"}},
+			map[string]string{"/d/2": "/d/1", "/d/3": "/d/2", "/d/1": "/d/3"}},
+		{"independent cycles", []string{"/x/a", "/x/b", "/y/a", "/y/b"},
+			[][2]string{{"/x/a", "/x/b"}, {"/x/b", "/x/a"}, {"/y/a", "/y/b"}, {"/y/b", "/y/a"}},
+			map[string]string{"/x/a": "/x/b", "/x/b": "/x/a", "/y/a": "/y/b", "/y/b": "/y/a"}},
+		{"directory after its entries", []string{"/d/sub/f", "/d/sub/g"},
+			[][2]string{{"/d/sub", "/d/new"}, {"/d/sub/f", "/d/sub/F"}, {"/d/sub/g", "/d/sub/G"}},
+			map[string]string{"/d/new/F": "/d/sub/f", "/d/new/G": "/d/sub/g"}},
+		{"nested directories", []string{"/d/a/b/f"},
+			[][2]string{{"/d/a", "/d/A"}, {"/d/a/b", "/d/a/B"}, {"/d/a/b/f", "/d/a/b/F"}},
+			map[string]string{"/d/A/B/F": "/d/a/b/f"}},
+	}
+	for _, tt := range tests {
+		for _, parallel := range []int{1, 4} {
+			t.Run(tt.name, func(t *testing.T) {
+				fsys := newTaggedTree(t, tt.files...)
+				var mappings []ReNameResult
+				for _, m := range tt.mappings {
+					mappings = append(mappings, ReNameResult{OldPath: m[0], NewPath: m[1]})
+				}
+				results := New(WithFS(fsys), WithParallel(parallel)).ApplyMapping(mappings, ModeNormal)
+				for _, result := range results {
+					if result.Status != StatusSuccess {
+						t.Errorf("parallel %d: %s: status %s (%s)", parallel, result.OldPath, result.Status, result.Message)
+					}
+				}
+				for path, from := range tt.want {
+					if got := readTag(t, fsys, path); got != from {
+						t.Errorf("parallel %d: %s holds %s, want %s", parallel, path, got, from)
+					}
+				}
+
+				// 回退后恢复原状
+				New(WithFS(fsys), WithParallel(parallel)).ApplyMapping(results, ModeUndo)
+				for _, file := range tt.files {
+					if got := readTag(t, fsys, file); got != file {
+						t.Errorf("parallel %d: after undo %s holds %s", parallel, file, got)
+					}
+				}
+			})
+		}
+	}
+}
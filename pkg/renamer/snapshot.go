@@ -0,0 +1,77 @@
+package renamer
+
+// Snapshot 返回 r 的独立副本，规则、文件列表和映射均被复制，之后对 r 的修改不影响副本。
+// opts 只作用于副本，可用于在不修改共享配置的情况下改变单次执行的设置，例如
+//
+//	batch := r.Snapshot(renamer.WithDryRun(true), renamer.WithProgress(fn))
+//	go batch.ApplyBatchContext(ctx)
+func (r *ReNamer) Snapshot(opts ...Option) *ReNamer {
+	r.mu.RLock()
+	s := r.clone()
+	s.Rules = cloneRules(r.Rules)
+	s.FileList = append([]string(nil), r.FileList...)
+	s.Mappings = append([]ReNameResult(nil), r.Mappings...)
+	r.mu.RUnlock()
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// clone returns a copy of the configuration of r sharing its slices. The
+// caller must hold the lock of r or own r exclusively.
+func (r *ReNamer) clone() *ReNamer {
+	return &ReNamer{
+		Rules:            r.Rules,
+		FileList:         r.FileList,
+		DryRun:           r.DryRun,
+		Mappings:         r.Mappings,
+		ProcessExtension: r.ProcessExtension,
+		Root:             r.Root,
+		AllowMove:        r.AllowMove,
+		AllowExec:        r.AllowExec,
+		Platform:         r.Platform,
+		ConflictPolicy:   r.ConflictPolicy,
+		Workers:          r.Workers,
+		ChunkSize:        r.ChunkSize,
+		Parallel:         r.Parallel,
+		fs:               r.fs,
+		progress:         r.progress,
+	}
+}
+
+// GetRules 返回规则列表的副本
+func (r *ReNamer) GetRules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return cloneRules(r.Rules)
+}
+
+// GetMappings 返回最近一次 ApplyBatch 生成的映射的副本
+func (r *ReNamer) GetMappings() []ReNameResult {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ReNameResult(nil), r.Mappings...)
+}
+
+// cloneRules copies rules including their options
+func cloneRules(rules []Rule) []Rule {
+	copied := make([]Rule, len(rules))
+	for i, rule := range rules {
+		copied[i] = rule.clone()
+	}
+	return copied
+}
+
+// clone returns a copy of the rule that does not share its options
+func (r Rule) clone() Rule {
+	if r.Options != nil {
+		options := make(map[string]string, len(r.Options))
+		for k, v := range r.Options {
+			options[k] = v
+		}
+		r.Options = options
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package renamer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	r := New(WithRules(Rule{Pattern: "^", Replace: "a_"}), WithDryRun(true))
+	r.AddFiles([]string{"/d/f"})
+
+	s := r.Snapshot(WithDryRun(false))
+	s.AddRule(Rule{Pattern: "$", Replace: "_b"})
+	s.AddFiles([]string{"/d/g"})
+
+	tests := []struct {
+		name   string
+		r      *ReNamer
+		rules  int
+		files  int
+		dryRun bool
+	}{
+		{"original", r, 1, 1, true},
+		{"snapshot", s, 2, 2, false},
+	}
+	for _, tt := range tests {
+		if got := len(tt.r.GetRules()); got != tt.rules {
+			t.Errorf("%s: %d rules, want %d", tt.name, got, tt.rules)
+		}
+		if got := len(tt.r.GetFiles()); got != tt.files {
+			t.Errorf("%s: %d files, want %d", tt.name, got, tt.files)
+		}
+		if tt.r.DryRun != tt.dryRun {
+			t.Errorf("%s: DryRun = %v, want %v", tt.name, tt.r.DryRun, tt.dryRun)
+		}
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	var files []string
+	for i := 0; i < 50; i++ {
+		files = append(files, fmt.Sprintf("/d/f%02d", i))
+	}
+	fsys := newMemTree(t, files...)
+	r := New(WithFS(fsys), WithDryRun(true), WithRules(Rule{Pattern: "^", Replace: "x_"}))
+	r.AddFiles(files)
+
+	// 批次使用开始时的快照，执行期间修改规则和设置不影响结果
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			results, err := r.ApplyBatchContext(context.Background())
+			if err != nil {
+				t.Errorf("ApplyBatchContext: %v", err)
+				return
+			}
+			for _, result := range results {
+				if base := filepath.Base(result.NewPath); base[:2] != "x_" || result.Status != StatusSuccess {
+					t.Errorf("%s -> %s: %s", result.OldPath, result.NewPath, result.Status)
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			id := r.AddRule(Rule{Pattern: "$", Replace: "_y"})
+			r.SetParallel(i)
+			r.SetProgress(func(Progress) {})
+			_ = r.GetMappings()
+			r.RemoveRuleByID(id)
+		}(i)
+	}
+	wg.Wait()
+}
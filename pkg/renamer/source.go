@@ -0,0 +1,305 @@
+package renamer
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FileSource 从文件和目录中查找要重命名的条目。直接给出的文件总是被选中，
+// 目录按以下条件遍历，目录本身不会被选中。
+//
+// 通配符使用 path.Match 的语法，MatchPath 为 true 时与相对于所遍历目录的路径
+// （以 / 分隔）匹配，此时 ** 可以匹配任意层目录，如 "**/*.jpg"；否则与名称匹配。
+// 规则的优先级：排除条件 > 隐藏文件 > 包含条件 > 大小和时间。
+type FileSource struct {
+	Paths []string // 文件或目录
+	Files bool     // 选中文件
+	Dirs  bool     // 选中目录
+
+	Include       []string // 文件的通配符，满足任一时选中，为空时不限制
+	IncludeRegexp []string // 文件的正则表达式，满足任一时选中，为空时不限制；与 Include 同时设置时需都满足
+	DirInclude    []string // 目录的通配符，满足任一时选中，为空时不限制；不影响是否遍历目录
+	Exclude       []string // 通配符，匹配的文件和目录不被选中，匹配的目录也不再遍历
+	ExcludeRegexp []string // 正则表达式，作用与 Exclude 相同
+	MatchPath     bool     // 与相对路径而不是名称匹配
+
+	MaxDepth   int  // 最大遍历深度，目录的直接内容为 1，0 表示不限制
+	SkipHidden bool // 跳过以 . 开头的文件和目录
+
+	MinSize        int64     // 文件的最小字节数，0 表示不限制
+	MaxSize        int64     // 文件的最大字节数，0 表示不限制
+	ModifiedAfter  time.Time // 只选中此时间之后修改的文件，零值表示不限制
+	ModifiedBefore time.Time // 只选中此时间之前修改的文件，零值表示不限制
+
+	FS      FS                           // 遍历使用的文件系统，为空时使用本地文件系统
+	OnError func(path string, err error) // 无法访问的路径，为空时忽略；遍历会继续
+}
+
+// NewFileSource 创建选中 paths 中所有文件的 FileSource
+func NewFileSource(paths ...string) *FileSource {
+	return &FileSource{
+		Paths: paths,
+		Files: true,
+		FS:    OSFS{},
+	}
+}
+
+// sourceFilter holds the validated patterns of a walk
+type sourceFilter struct {
+	*FileSource
+	include, exclude []*regexp.Regexp
+}
+
+// Walk 按顺序对每个选中的路径调用 fn，fn 返回错误时停止并返回该错误。
+// 通配符或正则表达式无效时在遍历前返回 ErrInvalidPattern 分类的错误
+func (s *FileSource) Walk(ctx context.Context, fn func(path string) error) error {
+	f, err := s.compile()
+	if err != nil {
+		return err
+	}
+	fsys := s.FS
+	if fsys == nil {
+		fsys = OSFS{}
+	}
+
+	for _, root := range s.Paths {
+		info, err := fsys.Stat(root)
+		if err != nil {
+			s.failed(root, err)
+			continue
+		}
+		if !info.IsDir() {
+			if err := fn(root); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = fsys.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				s.failed(p, err)
+				return nil
+			}
+			// 所遍历的目录本身不会被选中
+			if p == root {
+				return nil
+			}
+			rel, relErr := filepath.Rel(root, p)
+			if relErr != nil {
+				rel = d.Name()
+			}
+			rel = filepath.ToSlash(rel)
+			depth := strings.Count(rel, "/") + 1
+
+			if f.excluded(d.Name(), rel) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if s.Dirs && matchAny(s.DirInclude, f.subject(d.Name(), rel), s.MatchPath) {
+					if err := fn(p); err != nil {
+						return err
+					}
+				}
+				if s.MaxDepth > 0 && depth >= s.MaxDepth {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if s.Files && f.selected(p, d, rel) {
+				return fn(p)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// List 返回所有选中的路径
+func (s *FileSource) List(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := s.Walk(ctx, func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// Channel 在新的 goroutine 中遍历，把选中的路径依次发送到返回的 channel，可直接传给 Stream。
+// 遍历结束后关闭路径 channel，并向 errc 发送 Walk 的结果（可能为 nil）后关闭 errc；
+// ctx 取消时停止遍历
+func (s *FileSource) Channel(ctx context.Context) (paths <-chan string, errc <-chan error) {
+	out := make(chan string)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(out)
+		errs <- s.Walk(ctx, func(path string) error {
+			select {
+			case out <- path:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return out, errs
+}
+
+// failed reports an inaccessible path
+func (s *FileSource) failed(path string, err error) {
+	if s.OnError != nil {
+		s.OnError(path, err)
+	}
+}
+
+// compile validates the globs and compiles the regular expressions
+func (s *FileSource) compile() (*sourceFilter, error) {
+	for _, patterns := range [][]string{s.Include, s.DirInclude, s.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, newError(ErrInvalidPattern, "", "无效的通配符 '%s': %v", pattern, err)
+			}
+		}
+	}
+	f := &sourceFilter{FileSource: s}
+	var err error
+	if f.include, err = compileAll(s.IncludeRegexp); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compileAll(s.ExcludeRegexp); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, newError(ErrInvalidPattern, "", "无效的正则表达式 '%s': %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// subject returns the string the patterns are matched against
+func (f *sourceFilter) subject(name, rel string) string {
+	if f.MatchPath {
+		return rel
+	}
+	return name
+}
+
+// excluded reports whether an entry is excluded or hidden
+func (f *sourceFilter) excluded(name, rel string) bool {
+	if f.SkipHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	subject := f.subject(name, rel)
+	if len(f.Exclude) > 0 && matchAny(f.Exclude, subject, f.MatchPath) {
+		return true
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// selected reports whether a file matches the include, size and time filters
+func (f *sourceFilter) selected(p string, d fs.DirEntry, rel string) bool {
+	subject := f.subject(d.Name(), rel)
+	if !matchAny(f.Include, subject, f.MatchPath) {
+		return false
+	}
+	if len(f.include) > 0 {
+		matched := false
+		for _, re := range f.include {
+			if re.MatchString(subject) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if f.MinSize <= 0 && f.MaxSize <= 0 && f.ModifiedAfter.IsZero() && f.ModifiedBefore.IsZero() {
+		return true
+	}
+	info, err := d.Info()
+	if err != nil {
+		f.failed(p, err)
+		return false
+	}
+	switch {
+	case f.MinSize > 0 && info.Size() < f.MinSize:
+		return false
+	case f.MaxSize > 0 && info.Size() > f.MaxSize:
+		return false
+	case !f.ModifiedAfter.IsZero() && !info.ModTime().After(f.ModifiedAfter):
+		return false
+	case !f.ModifiedBefore.IsZero() && !info.ModTime().Before(f.ModifiedBefore):
+		return false
+	}
+	return true
+}
+
+// matchAny reports whether subject matches one of patterns, true if there
+// are none
+func matchAny(patterns []string, subject string, isPath bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if isPath {
+			if matchPath(strings.Split(pattern, "/"), strings.Split(subject, "/")) {
+				return true
+			}
+		} else if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches slash separated path segments, where a ** segment
+// matches any number of segments
+func matchPath(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for k := 0; k <= len(segments); k++ {
+				if matchPath(pattern[1:], segments[k:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
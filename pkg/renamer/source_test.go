@@ -0,0 +1,80 @@
+package renamer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFileSource(t *testing.T) {
+	fsys := newMemTree(t,
+		"/p/a.jpg", "/p/b.png", "/p/.hidden.jpg",
+		"/p/2024/c.jpg", "/p/2024/thumbs/d.jpg", "/p/2023/e.JPG",
+	)
+	if err := fsys.WriteFile("/p/big.jpg", make([]byte, 2048)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		source  FileSource
+		want    []string
+		wantErr bool
+	}{
+		{"all files", FileSource{Files: true}, []string{
+			"/p/.hidden.jpg", "/p/2023/e.JPG", "/p/2024/c.jpg", "/p/2024/thumbs/d.jpg", "/p/a.jpg", "/p/b.png", "/p/big.jpg"}, false},
+		{"include", FileSource{Files: true, Include: []string{"*.jpg"}, SkipHidden: true}, []string{
+			"/p/2024/c.jpg", "/p/2024/thumbs/d.jpg", "/p/a.jpg", "/p/big.jpg"}, false},
+		{"exclude prunes directories", FileSource{Files: true, Include: []string{"*.jpg"}, Exclude: []string{"thumbs", ".*"}}, []string{
+			"/p/2024/c.jpg", "/p/a.jpg", "/p/big.jpg"}, false},
+		{"match path", FileSource{Files: true, MatchPath: true, Include: []string{"**/2024/*.jpg"}}, []string{
+			"/p/2024/c.jpg"}, false},
+		{"regexp", FileSource{Files: true, IncludeRegexp: []string{`(?i)\.jpg$`}, SkipHidden: true, MaxDepth: 2}, []string{
+			"/p/2023/e.JPG", "/p/2024/c.jpg", "/p/a.jpg", "/p/big.jpg"}, false},
+		{"size", FileSource{Files: true, MinSize: 1024}, []string{"/p/big.jpg"}, false},
+		{"directories", FileSource{Dirs: true, DirInclude: []string{"20*"}}, []string{"/p/2023", "/p/2024"}, false},
+		{"max depth", FileSource{Files: true, MaxDepth: 1, SkipHidden: true}, []string{"/p/a.jpg", "/p/b.png", "/p/big.jpg"}, false},
+		{"invalid pattern", FileSource{Files: true, Include: []string{"["}}, nil, true},
+		{"invalid regexp", FileSource{Files: true, ExcludeRegexp: []string{"("}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			source.Paths = []string{"/p"}
+			source.FS = fsys
+			got, err := source.List(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSourceChannel(t *testing.T) {
+	fsys := newMemTree(t, "/p/a", "/p/b", "/p/c")
+	source := &FileSource{Paths: []string{"/p"}, Files: true, FS: fsys}
+
+	paths, errc := source.Channel(context.Background())
+	var got []string
+	for path := range paths {
+		got = append(got, path)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Channel: %v", err)
+	}
+	if want := []string{"/p/a", "/p/b", "/p/c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	paths, errc = source.Channel(ctx)
+	for range paths {
+	}
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("cancelled walk: err = %v, want %v", err, context.Canceled)
+	}
+}
@@ -12,6 +12,7 @@ const (
 	StatusPending ReNameStatus = iota // Not executed
 	StatusSuccess                     // Execution successful
 	StatusError                       // Execution failed
+	StatusSkipped                     // Skipped because of a conflict (ConflictSkip)
 )
 
 func (s ReNameStatus) String() string {
@@ -22,6 +23,8 @@ func (s ReNameStatus) String() string {
 		return "success"
 	case StatusError:
 		return "error"
+	case StatusSkipped:
+		return "skipped"
 	default:
 		return "unknown"
 	}
@@ -40,6 +43,8 @@ func (s *ReNameStatus) UnmarshalJSON(data []byte) error {
 		*s = StatusSuccess
 	case "error":
 		*s = StatusError
+	case "skipped":
+		*s = StatusSkipped
 	default:
 		return fmt.Errorf("unknown status: %s", str)
 	}
@@ -0,0 +1,214 @@
+package renamer
+
+import "context"
+
+// defaultChunkSize 流式处理时每批生成和执行的条目数
+const defaultChunkSize = 1000
+
+// WithChunkSize 设置流式处理时每批的条目数，0 表示使用默认值 1000
+func WithChunkSize(size int) Option {
+	return func(r *ReNamer) {
+		r.ChunkSize = size
+	}
+}
+
+// SetChunkSize 设置流式处理时每批的条目数，0 表示使用默认值 1000
+func (r *ReNamer) SetChunkSize(size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ChunkSize = size
+}
+
+// chunkSize returns the number of entries processed per chunk when streaming
+func (r *ReNamer) chunkSize() int {
+	if r.ChunkSize > 0 {
+		return r.ChunkSize
+	}
+	return defaultChunkSize
+}
+
+// Stream 流式处理 paths 中的文件，paths 关闭后结束。每读取 ChunkSize 个路径就生成映射并执行，
+// 结果通过 emit 逐条输出，不保存到 FileList 和 Mappings，内存占用与文件总数无关。
+//
+// 与 ApplyBatchContext 相比：{index} 等序号在整个流中连续，但冲突检测和 exec 等批量规则
+// 只作用于同一批内的条目，不同批之间的目标冲突在执行时按目标已存在处理；
+// 目录的映射保留到所有文件处理完后按由深到浅的顺序执行并输出，避免目录改名使后续批次中的路径失效。
+// 预览模式下整个流共用一个内存覆盖层，每批执行后只保留已发生的重命名。
+//
+// emit 返回错误或 ctx 被取消时停止读取 paths 并返回该错误，调用方需要自行结束向 paths 写入的 goroutine。
+func (r *ReNamer) Stream(ctx context.Context, paths <-chan string, emit func(ReNameResult) error) error {
+	s := r.newStream(emit)
+	chunk := make([]string, 0, s.r.chunkSize())
+	index := 0
+	flush := func() error {
+		mappings, err := s.r.generateMappings(ctx, chunk, index)
+		if err != nil {
+			return err
+		}
+		index += len(chunk)
+		chunk = chunk[:0]
+		return s.execute(ctx, mappings, ModeNormal)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case path, ok := <-paths:
+			if !ok {
+				if len(chunk) > 0 {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+				return s.finish(ctx, ModeNormal)
+			}
+			chunk = append(chunk, path)
+			if len(chunk) == cap(chunk) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// StreamMappings 流式执行 mappings 中的映射，mappings 关闭后结束，mode 的含义与 ApplyMapping 相同。
+// 每读取 ChunkSize 条映射执行一次，结果通过 emit 逐条输出，停止条件与 Stream 相同。
+//
+// ModeNormal 和 ModeError 下目录的处理方式与 Stream 相同。ModeUndo 下目录需要先于其中的条目恢复：
+// 每批中的目录先执行，源路径尚不存在的条目（通常位于之后才恢复的目录中）保留到流结束时再执行，
+// 因此按目录由浅到深、目录先于文件的顺序提供映射时内存占用最小
+func (r *ReNamer) StreamMappings(ctx context.Context, mappings <-chan ReNameResult, mode ReNameMode, emit func(ReNameResult) error) error {
+	s := r.newStream(emit)
+	chunk := make([]ReNameResult, 0, s.r.chunkSize())
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case mapping, ok := <-mappings:
+			if !ok {
+				if len(chunk) > 0 {
+					if err := s.execute(ctx, chunk, mode); err != nil {
+						return err
+					}
+				}
+				return s.finish(ctx, mode)
+			}
+			chunk = append(chunk, mapping)
+			if len(chunk) == cap(chunk) {
+				if err := s.execute(ctx, chunk, mode); err != nil {
+					return err
+				}
+				chunk = chunk[:0]
+			}
+		}
+	}
+}
+
+// stream holds the state shared by the chunks of a streamed run
+type stream struct {
+	r        *ReNamer     // snapshot of the renamer that executes the chunks
+	report   ProgressFunc // progress callback of the caller, may be nil
+	emit     func(ReNameResult) error
+	done     Progress       // progress of the finished chunks
+	current  Progress       // progress of the running chunk
+	deferred []ReNameResult // mappings executed at the end, see execute
+}
+
+// newStream prepares a snapshot of r that executes chunks and reports the
+// progress of the whole stream instead of single chunks
+func (r *ReNamer) newStream(emit func(ReNameResult) error) *stream {
+	s := &stream{r: r.Snapshot(), emit: emit}
+	s.report = s.r.progress
+	if s.r.DryRun {
+		s.r = s.r.preview()
+	}
+	s.r.progress = func(p Progress) {
+		s.current = p
+		if p.Current != "" {
+			s.notify(s.done.add(p))
+		}
+	}
+	return s
+}
+
+// notify sends the progress of the whole stream to the caller
+func (s *stream) notify(p Progress) {
+	if s.report != nil {
+		s.report(p)
+	}
+}
+
+// execute runs one chunk and emits its results. When applying, directory
+// mappings are kept for finish, so that renaming a directory does not move
+// entries of later chunks; finish runs them deepest-first. When undoing,
+// directories are restored first and the entries whose source does not exist
+// yet are kept for finish, which runs them once every directory is restored.
+func (s *stream) execute(ctx context.Context, mappings []ReNameResult, mode ReNameMode) error {
+	if mode == ModeUndo {
+		return s.undo(ctx, mappings)
+	}
+	files := make([]ReNameResult, 0, len(mappings))
+	for _, m := range mappings {
+		if m.OldPath != "" && s.r.isDir(m.OldPath) {
+			s.deferred = append(s.deferred, m)
+		} else {
+			files = append(files, m)
+		}
+	}
+	return s.run(ctx, files, mode)
+}
+
+// undo runs one chunk in ModeUndo, directories before the other entries
+func (s *stream) undo(ctx context.Context, mappings []ReNameResult) error {
+	var dirs, files []ReNameResult
+	for _, m := range mappings {
+		if m.NewPath != "" && s.r.isDir(m.NewPath) {
+			dirs = append(dirs, m)
+		} else {
+			files = append(files, m)
+		}
+	}
+	if err := s.run(ctx, dirs, ModeUndo); err != nil {
+		return err
+	}
+
+	ready := make([]ReNameResult, 0, len(files))
+	for _, m := range files {
+		if m.NewPath != "" && !exists(s.r.filesystem(), m.NewPath) {
+			s.deferred = append(s.deferred, m)
+		} else {
+			ready = append(ready, m)
+		}
+	}
+	return s.run(ctx, ready, ModeUndo)
+}
+
+// finish runs the deferred directory mappings and reports the final progress
+func (s *stream) finish(ctx context.Context, mode ReNameMode) error {
+	err := s.run(ctx, s.deferred, mode)
+	s.deferred = nil
+	s.notify(s.done)
+	return err
+}
+
+// run executes mappings and emits every result, also when ctx is cancelled
+func (s *stream) run(ctx context.Context, mappings []ReNameResult, mode ReNameMode) error {
+	if len(mappings) == 0 {
+		return ctx.Err()
+	}
+	results, err := s.r.applyMapping(ctx, mappings, mode)
+	if o, ok := s.r.fs.(*overlayFS); ok {
+		o.compact()
+	}
+	s.done = s.done.add(s.current)
+	s.done.Current = ""
+	s.current = Progress{}
+	for _, result := range results {
+		if emitErr := s.emit(result); emitErr != nil {
+			return emitErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package renamer
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newMemTree returns a MemFS containing the given files and their parents
+func newMemTree(t *testing.T, files ...string) *MemFS {
+	t.Helper()
+	fsys := NewMemFS()
+	for _, file := range files {
+		if err := fsys.MkdirAll(filepath.Dir(file)); err != nil {
+			t.Fatalf("MkdirAll(%s): %v", file, err)
+		}
+		if err := fsys.WriteFile(file, nil); err != nil {
+			t.Fatalf("WriteFile(%s): %v", file, err)
+		}
+	}
+	return fsys
+}
+
+// pathChan returns a closed channel holding paths
+func pathChan(paths ...string) <-chan string {
+	ch := make(chan string, len(paths))
+	for _, path := range paths {
+		ch <- path
+	}
+	close(ch)
+	return ch
+}
+
+func TestStream(t *testing.T) {
+	files := []string{"/d/a.txt", "/d/b.txt", "/d/c.txt"}
+	tests := []struct {
+		name     string
+		dryRun   bool
+		progress bool
+	}{
+		{"real without progress", false, false},
+		{"real with progress", false, true},
+		{"dry run with progress", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, files...)
+			reports := 0
+			opts := []Option{
+				WithFS(fsys),
+				WithDryRun(tt.dryRun),
+				WithChunkSize(2),
+				WithRules(Rule{Pattern: "^", Replace: "x_"}),
+			}
+			if tt.progress {
+				opts = append(opts, WithProgress(func(Progress) { reports++ }))
+			}
+
+			var results []ReNameResult
+			err := New(opts...).Stream(context.Background(), pathChan(files...), func(result ReNameResult) error {
+				results = append(results, result)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			if len(results) != len(files) {
+				t.Fatalf("got %d results, want %d", len(results), len(files))
+			}
+			for _, result := range results {
+				if result.Status != StatusSuccess {
+					t.Errorf("%s: status %s (%s)", result.OldPath, result.Status, result.Message)
+				}
+				_, err := fsys.Stat(result.NewPath)
+				if renamed := err == nil; renamed == tt.dryRun {
+					t.Errorf("%s: renamed = %v in dry run %v", result.NewPath, renamed, tt.dryRun)
+				}
+			}
+			if tt.progress && reports == 0 {
+				t.Error("progress was never reported")
+			}
+		})
+	}
+}
+
+func TestStreamMappingsUndo(t *testing.T) {
+	files := []string{"/d/a.txt", "/d/e/b.txt", "/d/e/c.txt", "/f/g.txt"}
+	paths := []string{"/d", "/d/a.txt", "/d/e", "/d/e/b.txt", "/d/e/c.txt", "/f/g.txt"}
+	tests := []struct {
+		name     string
+		reversed bool // 按目录由浅到深、目录先于文件的顺序回退
+		dryRun   bool
+	}{
+		{"in emitted order", false, false},
+		{"directories first", true, false},
+		{"dry run in emitted order", false, true},
+		{"dry run directories first", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newMemTree(t, files...)
+			original := listTree(t, fsys, "/")
+
+			var applied []ReNameResult
+			err := New(WithFS(fsys), WithChunkSize(2), WithRules(Rule{Pattern: "^", Replace: "x_"})).
+				Stream(context.Background(), pathChan(paths...), func(result ReNameResult) error {
+					applied = append(applied, result)
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			renamed := listTree(t, fsys, "/")
+
+			mappings := make(chan ReNameResult, len(applied))
+			for i := range applied {
+				if tt.reversed {
+					mappings <- applied[len(applied)-1-i]
+				} else {
+					mappings <- applied[i]
+				}
+			}
+			close(mappings)
+
+			var results []ReNameResult
+			err = New(WithFS(fsys), WithChunkSize(2), WithDryRun(tt.dryRun)).
+				StreamMappings(context.Background(), mappings, ModeUndo, func(result ReNameResult) error {
+					results = append(results, result)
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("StreamMappings: %v", err)
+			}
+			if len(results) != len(applied) {
+				t.Fatalf("got %d results, want %d", len(results), len(applied))
+			}
+			for _, result := range results {
+				if result.Status != StatusSuccess {
+					t.Errorf("%s: status %s (%s)", result.NewPath, result.Status, result.Message)
+				}
+			}
+
+			want := original
+			if tt.dryRun {
+				want = renamed
+			}
+			if got := listTree(t, fsys, "/"); !reflect.DeepEqual(got, want) {
+				t.Errorf("tree after undo = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package renamer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// placeholderPattern 模板中的变量占位符：{name} 或 {name|filter:arg|...}，参数中的 \| 和 \: 表示字面字符
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)((?:\|(?:[^{}|\\]|\\.)+)*)\}`)
+
+// builtinVars 内置及通过 RegisterPlaceholder 注册的模板变量，命名捕获组等同名变量优先
+var builtinVars = map[string]func(ctx *RuleContext) (string, bool){
+	// 原文件名（不含扩展名）
+	"name": func(ctx *RuleContext) (string, bool) {
+		if ctx.Path == "" {
+			return "", false
+		}
+		base := filepath.Base(ctx.Path)
+		return base[:len(base)-len(pathExt(ctx))], true
+	},
+	// 扩展名（不含点号）
+	"ext": func(ctx *RuleContext) (string, bool) {
+		if ctx.Path == "" {
+			return "", false
+		}
+		return strings.TrimPrefix(pathExt(ctx), "."), true
+	},
+	// 文件在批次中的序号，从 1 开始
+	"index": func(ctx *RuleContext) (string, bool) {
+		return strconv.Itoa(ctx.Index + 1), true
+	},
+	"date": func(*RuleContext) (string, bool) {
+		return time.Now().Format("2006-01-02"), true
+	},
+	"time": func(*RuleContext) (string, bool) {
+		return time.Now().Format("15:04:05"), true
+	},
+	"datetime": func(*RuleContext) (string, bool) {
+		return time.Now().Format("2006-01-02_15:04:05"), true
+	},
+}
+
+// pathExt 返回原文件的扩展名，目录没有扩展名
+func pathExt(ctx *RuleContext) string {
+	if isDir(ctx.filesystem(), ctx.Path) {
+		return ""
+	}
+	return filepath.Ext(ctx.Path)
+}
+
+// setVar 保存模板变量，同一文件后续的规则也可以引用
+func (c *RuleContext) setVar(name, value string) {
+	if c.Vars == nil {
+		c.Vars = make(map[string]string)
+	}
+	c.Vars[name] = value
+}
+
+// lookup 查找模板变量，先查规则链中保存的变量，再查内置变量
+func (c *RuleContext) lookup(name string) (string, bool) {
+	if v, ok := c.Vars[name]; ok {
+		return v, true
+	}
+	if builtin, ok := lookupPlaceholder(name); ok {
+		return builtin(c)
+	}
+	return "", false
+}
+
+// expandTemplate 替换模板中的占位符，并依次应用过滤器，未定义的变量保持原样。
+// regexp 为 true 时结果还要交给 Regexp.Expand：变量值中的 $ 被转义，
+// 紧跟在 $ 之后的 ${name}、${1} 属于 Expand 的语法，不作为占位符处理（$$ 表示字面的 $）
+func expandTemplate(template string, ctx *RuleContext, regexp bool) (string, error) {
+	var sb strings.Builder
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		if regexp && dollars(template[:m[0]])%2 == 1 {
+			continue
+		}
+		value, ok := ctx.lookup(template[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		if m[4] < m[5] {
+			var err error
+			for _, spec := range splitEscaped(template[m[4]+1:m[5]], '|') {
+				if value, err = applyFilter(value, spec, ctx); err != nil {
+					return template, err
+				}
+			}
+		}
+		if regexp {
+			value = escapeExpand(value)
+		}
+		sb.WriteString(template[last:m[0]])
+		sb.WriteString(value)
+		last = m[1]
+	}
+	sb.WriteString(template[last:])
+	return sb.String(), nil
+}
+
+// dollars 返回 s 末尾连续的 $ 个数
+func dollars(s string) int {
+	return len(s) - len(strings.TrimRight(s, "$"))
+}
+
+// escapeExpand 转义 Regexp.Expand 中的 $ 符号
+func escapeExpand(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// captureGroups 将匹配 m 中的命名捕获组保存为模板变量，未参与匹配的组为空字符串
+func captureGroups(re *regexp.Regexp, s string, m []int, ctx *RuleContext) {
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		value := ""
+		if m[2*i] >= 0 {
+			value = s[m[2*i]:m[2*i+1]]
+		}
+		ctx.setVar(name, value)
+	}
+}
@@ -0,0 +1,47 @@
+package renamer
+
+import "testing"
+
+func TestNamedCaptureGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		path     string
+		in, want string
+	}{
+		{"reorder", Rule{Pattern: `^(?P<artist>.+?) - (?P<title>.+)$`, Replace: "{title} ({artist})"}, "", "Daft Punk - Around the World", "Around the World (Daft Punk)"},
+		{"filter", Rule{Pattern: `^(?P<artist>.+?) - `, Replace: "{artist|upper}_"}, "", "Daft Punk - One More Time", "DAFT PUNK_One More Time"},
+		{"unknown variable kept", Rule{Pattern: `^`, Replace: "{missing}_"}, "", "a", "{missing}_a"},
+		{"numbered groups", Rule{Pattern: `^(\w+)_(\w+)$`, Replace: "${2}_${1}"}, "", "one_two", "two_one"},
+		{"named groups with $", Rule{Pattern: `^(?P<artist>.+?) - (?P<title>.+)$`, Replace: "${title}_${artist}"}, "", "Foo - Bar", "Bar_Foo"},
+		{"numbered group before text", Rule{Pattern: `^(?P<a>\w+)$`, Replace: "${a}x"}, "", "foo", "foox"},
+		{"literal $ before placeholder", Rule{Pattern: `^(?P<a>\w+)$`, Replace: "$${a}"}, "", "foo", "$foo"},
+		{"builtin variable", Rule{Pattern: `^`, Replace: "{index|pad:3}_"}, "/d/a.txt", "a", "001_a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.ApplyContext(tt.in, &RuleContext{Path: tt.path})
+			if err != nil {
+				t.Fatalf("ApplyContext(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyContext(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureGroupsCarryToLaterRules(t *testing.T) {
+	ctx := &RuleContext{Path: "/d/x", fs: NewMemFS()}
+	name, err := Rule{Pattern: `^(?P<show>\w+)\.S(?P<season>\d+)$`, Replace: "$0"}.ApplyContext("Lost.S01", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err = Rule{Pattern: `^.*$`, Replace: "{show} season {season}"}.ApplyContext(name, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Lost season 01"; name != want {
+		t.Errorf("got %q, want %q", name, want)
+	}
+}
@@ -0,0 +1,282 @@
+package renamer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 西里尔字母转写方案
+const (
+	CyrillicBGN      = "bgn"      // BGN/PCGN：Щука → Shchuka
+	CyrillicPassport = "passport" // 俄罗斯护照（ICAO 9303）：Юлия → Iuliia
+)
+
+// 日文假名转写方案
+const (
+	KanaHepburn = "hepburn" // 平文式：しち → shichi
+	KanaKunrei  = "kunrei"  // 训令式：しち → siti
+)
+
+// defaultAllowedChars 删除模式下默认保留的字符集（正则字符类）
+const defaultAllowedChars = `A-Za-z0-9 ._-`
+
+// latinFolding 无法通过去除附加符号得到 ASCII 的拉丁字母
+var latinFolding = map[rune]string{
+	'ß': "ss", 'ẞ': "SS", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+	'þ': "th", 'Þ': "Th", 'ł': "l", 'Ł': "L", 'ı': "i", 'ħ': "h",
+	'Ħ': "H", 'ŋ': "ng", 'Ŋ': "Ng", 'ĸ': "q", 'ſ': "s", 'ƒ': "f",
+}
+
+// cyrillicBGN BGN/PCGN 方案（小写字母）
+var cyrillicBGN = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// 乌克兰语、白俄罗斯语
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "w",
+	// 塞尔维亚语、马其顿语
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+	'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+}
+
+// cyrillicPassport 与 BGN/PCGN 不同的护照方案字母
+var cyrillicPassport = map[rune]string{
+	'й': "i", 'ъ': "ie", 'ю': "iu", 'я': "ia", 'є': "ie", 'ї': "i",
+}
+
+// greekDigraphs ELOT 743 中按双字母转写的组合（小写）
+var greekDigraphs = map[string]string{
+	"ου": "ou", "αυ": "av", "ευ": "ev", "ηυ": "iv",
+	"γγ": "ng", "γκ": "gk", "γξ": "nx", "γχ": "nch",
+}
+
+// greekLetters ELOT 743 单字母转写（小写）
+var greekLetters = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// kanaHepburn 平假名的平文式转写，片假名先转换为平假名再查表
+var kanaHepburn = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// kanaKunrei 与平文式不同的训令式假名
+var kanaKunrei = map[rune]string{
+	'し': "si", 'じ': "zi", 'ち': "ti", 'ぢ': "zi", 'つ': "tu", 'ふ': "hu",
+}
+
+// transliterator 将文件名转写为 ASCII
+type transliterator struct {
+	cyrillic map[rune]string
+	kana     map[rune]string
+}
+
+func newTransliterator(cyrillicScheme, kanaScheme string) (*transliterator, error) {
+	t := &transliterator{cyrillic: cyrillicBGN, kana: kanaHepburn}
+
+	switch cyrillicScheme {
+	case "", CyrillicBGN:
+	case CyrillicPassport:
+		t.cyrillic = mergeTable(cyrillicBGN, cyrillicPassport)
+	default:
+		return nil, fmt.Errorf("未知的西里尔字母转写方案 '%s'", cyrillicScheme)
+	}
+
+	switch kanaScheme {
+	case "", KanaHepburn:
+	case KanaKunrei:
+		t.kana = mergeTable(kanaHepburn, kanaKunrei)
+	default:
+		return nil, fmt.Errorf("未知的假名转写方案 '%s'", kanaScheme)
+	}
+	return t, nil
+}
+
+// mergeTable 返回以 overrides 覆盖 base 后的新表
+func mergeTable(base, overrides map[rune]string) map[rune]string {
+	table := make(map[rune]string, len(base))
+	for k, v := range base {
+		table[k] = v
+	}
+	for k, v := range overrides {
+		table[k] = v
+	}
+	return table
+}
+
+// toHiragana 将片假名转换为对应的平假名
+func toHiragana(c rune) rune {
+	if c >= 'ァ' && c <= 'ヶ' {
+		return c - 0x60
+	}
+	return c
+}
+
+// isSmallKana 判断是否为拗音或外来音使用的小写假名
+func isSmallKana(c rune) bool {
+	return strings.ContainsRune("ぁぃぅぇぉゃゅょゎ", c)
+}
+
+// Transliterate 转写字符串
+func (t *transliterator) Transliterate(s string) string {
+	runes := []rune(norm.NFC.String(s))
+	var sb strings.Builder
+	sokuon := false // 促音，重复下一个音节的辅音
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		lower := unicode.ToLower(c)
+
+		// 日文假名
+		if h := toHiragana(c); h == 'っ' {
+			sokuon = true
+			continue
+		} else if romaji, ok := t.kana[h]; ok {
+			if i+1 < len(runes) && isSmallKana(toHiragana(runes[i+1])) && !isSmallKana(h) {
+				romaji = combineKana(romaji, t.kana[toHiragana(runes[i+1])])
+				i++
+			}
+			if sokuon {
+				romaji = doubleConsonant(romaji)
+				sokuon = false
+			}
+			sb.WriteString(romaji)
+			continue
+		} else if c == 'ー' {
+			// 长音符号重复前一个元音
+			if v := lastVowel(sb.String()); v != 0 {
+				sb.WriteRune(v)
+			}
+			continue
+		}
+		sokuon = false
+
+		// 希腊字母双字母组合
+		if i+1 < len(runes) {
+			pair := string(stripMarks(lower)) + string(stripMarks(unicode.ToLower(runes[i+1])))
+			if latin, ok := greekDigraphs[pair]; ok {
+				sb.WriteString(matchCase(latin, runes, i))
+				i++
+				continue
+			}
+		}
+
+		if latin, ok := t.cyrillic[lower]; ok {
+			sb.WriteString(matchCase(latin, runes, i))
+		} else if latin, ok := greekLetters[stripMarks(lower)]; ok {
+			sb.WriteString(matchCase(latin, runes, i))
+		} else if latin, ok := latinFolding[c]; ok {
+			sb.WriteString(latin)
+		} else {
+			sb.WriteRune(stripMarks(c))
+		}
+	}
+	return sb.String()
+}
+
+// stripMarks 去掉字符上的附加符号（如 é → e、ά → α）
+func stripMarks(c rune) rune {
+	decomposed := []rune(norm.NFD.String(string(c)))
+	for _, m := range decomposed[1:] {
+		if !unicode.Is(unicode.Mn, m) {
+			// 不是附加符号的分解（如韩文音节），保持原样
+			return c
+		}
+	}
+	return decomposed[0]
+}
+
+// matchCase 按原字符的大小写调整转写结果：单个大写字母首字母大写，连续大写时全部大写
+func matchCase(latin string, runes []rune, i int) string {
+	if latin == "" || !unicode.IsUpper(runes[i]) {
+		return latin
+	}
+	prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+	nextUpper := i+1 < len(runes) && unicode.IsUpper(runes[i+1])
+	if prevUpper || nextUpper {
+		return strings.ToUpper(latin)
+	}
+	return strings.ToUpper(latin[:1]) + latin[1:]
+}
+
+// combineKana 组合拗音：き+ゃ → kya，し+ゃ → sha，ふ+ぁ → fa
+func combineKana(base, small string) string {
+	stem := strings.TrimRight(base, "aiueo")
+	if strings.HasPrefix(small, "y") {
+		if strings.HasSuffix(stem, "sh") || strings.HasSuffix(stem, "ch") || stem == "j" {
+			return stem + small[1:]
+		}
+		return stem + small
+	}
+	if stem == "" {
+		// う+ぃ → wi
+		stem = "w"
+	}
+	return stem + small
+}
+
+// doubleConsonant 处理促音：重复音节的第一个辅音，ch 前使用 t
+func doubleConsonant(romaji string) string {
+	if romaji == "" || strings.ContainsRune("aiueon", rune(romaji[0])) {
+		return romaji
+	}
+	if strings.HasPrefix(romaji, "ch") {
+		return "t" + romaji
+	}
+	return romaji[:1] + romaji
+}
+
+// lastVowel 返回字符串中最后一个字符（为元音时）
+func lastVowel(s string) rune {
+	if s == "" {
+		return 0
+	}
+	last := rune(s[len(s)-1])
+	if strings.ContainsRune("aiueo", last) {
+		return last
+	}
+	return 0
+}
+
+// applyTransliterate 转写规则的处理函数
+func applyTransliterate(r Rule, filename string, ctx *RuleContext) (string, error) {
+	t, err := newTransliterator(r.option("cyrillic", CyrillicBGN), r.option("kana", KanaHepburn))
+	if err != nil {
+		return filename, err
+	}
+	result := t.Transliterate(filename)
+
+	if r.option("strip", "false") == "true" {
+		re, err := ctx.compile(`[^` + r.option("allowed", defaultAllowedChars) + `]`)
+		if err != nil {
+			return filename, fmt.Errorf("无效的字符集 '%s': %v", r.option("allowed", defaultAllowedChars), err)
+		}
+		result = re.ReplaceAllLiteralString(result, r.option("replacement", ""))
+	}
+	return result, nil
+}
@@ -0,0 +1,29 @@
+package renamer
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	rf := NewRuleFactory()
+	tests := []struct {
+		name     string
+		rule     Rule
+		in, want string
+	}{
+		{"latin", rf.Transliterate("", ""), "Crème brûlée", "Creme brulee"},
+		{"cyrillic bgn", rf.Transliterate("", ""), "Щука", "Shchuka"},
+		{"cyrillic passport", rf.Transliterate(CyrillicPassport, ""), "Юлия", "Iuliia"},
+		{"kana hepburn", rf.Transliterate("", ""), "しち", "shichi"},
+		{"kana kunrei", rf.Transliterate("", KanaKunrei), "しち", "siti"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Apply(tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
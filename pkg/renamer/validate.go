@@ -0,0 +1,206 @@
+package renamer
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Platform 目标平台，决定文件名的校验规则
+type Platform string
+
+const (
+	PlatformAuto     Platform = ""         // 当前运行平台
+	PlatformWindows  Platform = "windows"  // Windows (NTFS/FAT)
+	PlatformLinux    Platform = "linux"    // Linux 等 POSIX 系统
+	PlatformDarwin   Platform = "darwin"   // macOS
+	PlatformPortable Platform = "portable" // 同时满足所有平台
+)
+
+// maxNameLength 单个路径分量的最大长度（Windows 为 UTF-16 单元数，其他平台为字节数）
+const maxNameLength = 255
+
+// windowsForbiddenChars Windows 文件名中禁止使用的字符
+const windowsForbiddenChars = `<>:"/\|?*`
+
+// windowsReservedNames Windows 保留的设备名，不区分大小写，带扩展名同样无效
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"COM¹": true, "COM²": true, "COM³": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"LPT¹": true, "LPT²": true, "LPT³": true,
+}
+
+// ParsePlatform 解析平台名称，空字符串或 "auto" 表示当前运行平台
+func ParsePlatform(name string) (Platform, error) {
+	switch p := Platform(strings.ToLower(name)); p {
+	case PlatformAuto, PlatformWindows, PlatformLinux, PlatformDarwin, PlatformPortable:
+		return p, nil
+	case "auto":
+		return PlatformAuto, nil
+	default:
+		return PlatformAuto, fmt.Errorf("未知的平台: %s", name)
+	}
+}
+
+// resolve 将 PlatformAuto 转换为当前运行平台
+func (p Platform) resolve() Platform {
+	if p != PlatformAuto {
+		return p
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return PlatformWindows
+	case "darwin", "ios":
+		return PlatformDarwin
+	default:
+		return PlatformLinux
+	}
+}
+
+func (p Platform) windowsRules() bool {
+	p = p.resolve()
+	return p == PlatformWindows || p == PlatformPortable
+}
+
+// isForbidden 判断字符在目标平台的文件名中是否被禁止
+func (p Platform) isForbidden(c rune) bool {
+	if c < 0x20 || c == 0x7f || c == '/' {
+		return true
+	}
+	switch p.resolve() {
+	case PlatformWindows, PlatformPortable:
+		return strings.ContainsRune(windowsForbiddenChars, c)
+	case PlatformDarwin:
+		return c == ':'
+	}
+	return false
+}
+
+// nameLength 按目标平台的计量方式返回文件名长度
+func (p Platform) nameLength(name string) int {
+	n := len(name)
+	if p.resolve() == PlatformWindows {
+		n = len(utf16.Encode([]rune(name)))
+	}
+	return n
+}
+
+// isReservedName 判断文件名是否为 Windows 保留设备名
+func isReservedName(name string) bool {
+	base := name
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	base = strings.TrimRight(base, " ")
+	return windowsReservedNames[strings.ToUpper(base)]
+}
+
+// ValidateName 检查单个文件名（不含路径）在目标平台上是否合法
+func ValidateName(name string, platform Platform) error {
+	if name == "" {
+		return newError(ErrInvalidName, name, "文件名为空")
+	}
+	if !utf8.ValidString(name) {
+		return newError(ErrInvalidName, name, "文件名 %q 不是有效的 UTF-8", name)
+	}
+	for _, c := range name {
+		if platform.isForbidden(c) {
+			return newError(ErrInvalidName, name, "文件名 %q 包含非法字符 %q", name, c)
+		}
+	}
+	if platform.windowsRules() {
+		if isReservedName(name) {
+			return newError(ErrInvalidName, name, "文件名 %q 是 Windows 保留名", name)
+		}
+		if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+			return newError(ErrInvalidName, name, "文件名 %q 以点或空格结尾", name)
+		}
+	}
+	if platform.nameLength(name) > maxNameLength {
+		return newError(ErrInvalidName, name, "文件名 %q 超过 %d 个字符", name, maxNameLength)
+	}
+	return nil
+}
+
+// SanitizeName 将文件名修正为目标平台上的合法名称：
+// 非法字符替换为 replacement，去掉末尾的点和空格，保留名加上 replacement 后缀，
+// 超长时在保留扩展名的前提下截断。
+func SanitizeName(name string, platform Platform, replacement string) string {
+	// 替换字符本身必须合法
+	if strings.IndexFunc(replacement, platform.isForbidden) >= 0 {
+		replacement = ""
+	}
+
+	var sb strings.Builder
+	for _, c := range strings.ToValidUTF8(name, replacement) {
+		if platform.isForbidden(c) {
+			sb.WriteString(replacement)
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	name = sb.String()
+
+	if platform.windowsRules() {
+		name = strings.TrimRight(name, ". ")
+		if isReservedName(name) {
+			base, ext := splitFirstDot(name)
+			if replacement == "" {
+				base += "_"
+			} else {
+				base += replacement
+			}
+			name = base + ext
+		}
+	}
+
+	name = truncateName(name, platform)
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// splitFirstDot 在第一个点处拆分文件名
+func splitFirstDot(name string) (string, string) {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return name[:i], name[i:]
+	}
+	return name, ""
+}
+
+// truncateName 截断过长的文件名，尽量保留扩展名
+func truncateName(name string, platform Platform) string {
+	if platform.nameLength(name) <= maxNameLength {
+		return name
+	}
+
+	ext := ""
+	if i := strings.LastIndexByte(name, '.'); i > 0 && platform.nameLength(name[i:]) < maxNameLength/2 {
+		name, ext = name[:i], name[i:]
+	}
+	limit := maxNameLength - platform.nameLength(ext)
+	for platform.nameLength(name) > limit {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if platform.windowsRules() {
+		name = strings.TrimRight(name, ". ")
+	}
+	return name + ext
+}
+
+// applySanitize 清理非法字符规则的处理函数
+func applySanitize(r Rule, filename string, ctx *RuleContext) (string, error) {
+	platform, err := ParsePlatform(r.option("platform", ""))
+	if err != nil {
+		return filename, err
+	}
+	return SanitizeName(filename, platform, r.option("replacement", "_")), nil
+}
@@ -0,0 +1,66 @@
+package renamer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform Platform
+		wantErr  bool
+	}{
+		{"report.txt", PlatformWindows, false},
+		{"", PlatformLinux, true},
+		{"a:b.txt", PlatformWindows, true},
+		{"a:b.txt", PlatformLinux, false},
+		{"a/b", PlatformLinux, true},
+		{"CON", PlatformWindows, true},
+		{"con.txt", PlatformWindows, true},
+		{"console.txt", PlatformWindows, false},
+		{"name.", PlatformWindows, true},
+		{"name ", PlatformPortable, true},
+		{"name.", PlatformLinux, false},
+		{"a\x00b", PlatformLinux, true},
+		{"\xff", PlatformLinux, true},
+		{strings.Repeat("a", 255), PlatformLinux, false},
+		{strings.Repeat("a", 256), PlatformLinux, true},
+		{strings.Repeat("汉", 100), PlatformLinux, true},
+		{strings.Repeat("汉", 100), PlatformWindows, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.platform)+"/"+tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name, tt.platform)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q, %s) = %v, wantErr %v", tt.name, tt.platform, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name        string
+		platform    Platform
+		replacement string
+		want        string
+	}{
+		{"a:b?.txt", PlatformWindows, "_", "a_b_.txt"},
+		{"a:b?.txt", PlatformLinux, "_", "a:b?.txt"},
+		{"a:b.txt", PlatformWindows, ":", "ab.txt"},
+		{"name. ", PlatformWindows, "_", "name"},
+		{"CON.txt", PlatformWindows, "_", "CON_.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.platform)+"/"+tt.name, func(t *testing.T) {
+			got := SanitizeName(tt.name, tt.platform, tt.replacement)
+			if got != tt.want {
+				t.Errorf("SanitizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+			if err := ValidateName(got, tt.platform); err != nil {
+				t.Errorf("sanitized name is invalid: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package renamer
+
+import (
+	"fmt"
+
+	"golang.org/x/text/width"
+)
+
+// 全角半角转换方向
+const (
+	WidthHalf = "half" // 全角转半角：ＡＢＣ１２３ → ABC123
+	WidthFull = "full" // 半角转全角：ABC123 → ＡＢＣ１２３
+)
+
+// applyWidth 全角半角转换规则的处理函数
+func applyWidth(r Rule, filename string, ctx *RuleContext) (string, error) {
+	switch to := r.option("to", WidthHalf); to {
+	case WidthHalf:
+		return width.Narrow.String(filename), nil
+	case WidthFull:
+		return width.Widen.String(filename), nil
+	default:
+		return filename, fmt.Errorf("未知的全角半角转换方向 '%s'", to)
+	}
+}